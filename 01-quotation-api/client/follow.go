@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultFollowRetry = 3 * time.Second
+	// O servidor envia heartbeats a cada 15s; sem nada por mais tempo que isso a conexão é refeita
+	followIdleTimeout = 45 * time.Second
+)
+
+var (
+	errConnectTimeout = errors.New("connect timeout")
+	errStreamIdle     = errors.New("stream idle")
+)
+
+type streamEvent struct {
+	id    string
+	event string
+	data  string
+}
+
+// Acompanha /quotes/stream e grava cada cotação recebida. Ao perder a
+// conexão, reconecta enviando o último id recebido para não perder cotações.
+// Termina com SIGINT/SIGTERM; só falha se a primeira conexão não der certo.
+func follow(config Config) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	lastID := ""
+	retry := defaultFollowRetry
+	connected := false
+	for {
+		code := streamQuotations(ctx, config, &lastID, &retry, &connected)
+		if ctx.Err() != nil {
+			return exitOK
+		}
+		if !connected || code == exitStoreError {
+			return code
+		}
+		log.Printf("Stream interrupted, reconnecting in %s\n", retry)
+		select {
+		case <-ctx.Done():
+			return exitOK
+		case <-time.After(retry):
+		}
+	}
+}
+
+func streamQuotations(ctx context.Context, config Config, lastID *string, retry *time.Duration, connected *bool) int {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	endpoint := strings.TrimSuffix(config.ServerURL, "/") + "/quotes/stream?pairs=" + url.QueryEscape(config.Pair)
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		log.Println("error creating request")
+		return exitError
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	authorize(req, config)
+	if *lastID != "" {
+		req.Header.Set("Last-Event-ID", *lastID)
+	}
+
+	// -timeout vale só até a resposta do servidor; depois o stream fica aberto
+	connectTimer := time.AfterFunc(config.Timeout, func() { cancel(errConnectTimeout) })
+	resp, err := http.DefaultClient.Do(req)
+	connectTimer.Stop()
+	if err != nil {
+		if context.Cause(ctx) == errConnectTimeout {
+			log.Println("Request Timeout " + config.ServerURL)
+			return exitTimeout
+		}
+		log.Println("error executing request: " + err.Error())
+		return exitHTTPError
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		res, _ := io.ReadAll(resp.Body)
+		return problemExitCode(resp, res)
+	}
+	*connected = true
+	log.Println("Following " + config.Pair)
+
+	idle := time.AfterFunc(followIdleTimeout, func() { cancel(errStreamIdle) })
+	defer idle.Stop()
+	var storeErr error
+	readEvents(resp.Body, func() { idle.Reset(followIdleTimeout) }, func(e streamEvent) error {
+		if e.id != "" {
+			*lastID = e.id
+		}
+		if e.event != "quote" {
+			return nil
+		}
+		var q Quotation
+		if err := json.Unmarshal([]byte(e.data), &q); err != nil || q.Bid.Sign() <= 0 {
+			log.Println("error unmarshalling event " + e.id)
+			return nil
+		}
+		storeErr = Store(config, &q, time.Now())
+		return storeErr
+	}, retry)
+	if storeErr != nil {
+		log.Println("error storing quotation: " + storeErr.Error())
+		return exitStoreError
+	}
+	if context.Cause(ctx) == errStreamIdle {
+		log.Println("No data from server for " + followIdleTimeout.String())
+	}
+	return exitOK
+}
+
+// Lê eventos no formato text/event-stream até o fim do corpo. Linhas que
+// começam com ":" (heartbeats) só mantêm a conexão viva.
+func readEvents(body io.Reader, alive func(), handle func(streamEvent) error, retry *time.Duration) error {
+	scanner := bufio.NewScanner(body)
+	var e streamEvent
+	for scanner.Scan() {
+		alive()
+		line := scanner.Text()
+		if line == "" {
+			if e.data != "" || e.id != "" {
+				if e.event == "" {
+					e.event = "message"
+				}
+				if err := handle(e); err != nil {
+					return err
+				}
+			}
+			e = streamEvent{}
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			e.id = value
+		case "event":
+			e.event = value
+		case "data":
+			if e.data != "" {
+				e.data += "\n"
+			}
+			e.data += value
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil && ms > 0 {
+				*retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+	return scanner.Err()
+}
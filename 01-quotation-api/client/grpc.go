@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/isaacmirandacampos/go-expert/quotation-api/internal/decimal"
+	"github.com/isaacmirandacampos/go-expert/quotation-api/quotepb"
+)
+
+// Busca a cotação pelo QuoteService, com os mesmos códigos de saída do HTTP
+func fetchQuotationGRPC(ctx context.Context, config Config) (*Quotation, int) {
+	conn, err := grpc.NewClient(config.GRPCAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Println("error creating gRPC client: " + err.Error())
+		return nil, exitError
+	}
+	defer conn.Close()
+	if config.APIKey != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+config.APIKey)
+	}
+	q, err := quotepb.NewQuoteServiceClient(conn).GetQuote(ctx, &quotepb.GetQuoteRequest{Pair: config.Pair})
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			log.Println("Request Timeout " + config.GRPCAddr)
+			return nil, exitTimeout
+		}
+		return nil, grpcExitCode(err)
+	}
+	log.Println("Response received:", q.String())
+	quotation, err := newQuotation(q)
+	if err != nil || quotation.Bid.Sign() <= 0 {
+		log.Println("error decoding response")
+		return nil, exitDecodeError
+	}
+	return quotation, exitOK
+}
+
+func newQuotation(q *quotepb.Quote) (*Quotation, error) {
+	bid, err := decimal.Parse(q.GetBid())
+	if err != nil {
+		return nil, err
+	}
+	quotation := &Quotation{
+		Pair:     q.GetPair(),
+		Name:     q.GetName(),
+		Bid:      bid,
+		Provider: q.GetProvider(),
+		Stale:    q.GetStale(),
+		Age:      q.GetAgeSeconds(),
+	}
+	if q.Timestamp != nil {
+		quotation.Timestamp = strconv.FormatInt(q.Timestamp.GetSeconds(), 10)
+	}
+	for _, field := range []struct {
+		value *string
+		dest  *decimal.NullDecimal
+	}{{q.Ask, &quotation.Ask}, {q.High, &quotation.High}, {q.Low, &quotation.Low}} {
+		if field.value == nil {
+			continue
+		}
+		d, err := decimal.Parse(*field.value)
+		if err != nil {
+			return nil, err
+		}
+		*field.dest = decimal.NullDecimal{Decimal: d, Valid: true}
+	}
+	return quotation, nil
+}
+
+// O Reason do ErrorInfo diz se a falha foi do provedor ou do banco do
+// servidor; sem ele, é uma falha de conexão ou de requisição
+func grpcExitCode(err error) int {
+	st := status.Convert(err)
+	log.Printf("gRPC status: %s: %s\n", st.Code(), st.Message())
+	for _, detail := range st.Details() {
+		info, ok := detail.(*errdetails.ErrorInfo)
+		if !ok {
+			continue
+		}
+		switch info.GetReason() {
+		case "UPSTREAM_TIMEOUT":
+			return exitUpstreamTimeout
+		case "UPSTREAM_BAD_PAYLOAD", "UPSTREAM_FAILURE":
+			return exitUpstreamError
+		case "DATABASE_TIMEOUT", "DATABASE_FAILURE":
+			return exitUnavailable
+		}
+	}
+	if st.Code() == codes.DeadlineExceeded {
+		return exitTimeout
+	}
+	return exitHTTPError
+}
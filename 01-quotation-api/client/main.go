@@ -4,65 +4,182 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"flag"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
+	"strings"
 	"time"
+
+	"github.com/isaacmirandacampos/go-expert/quotation-api/internal/decimal"
+)
+
+// Códigos de saída, para que scripts consigam diferenciar as falhas
+const (
+	exitOK          = 0
+	exitError       = 1
+	exitUsage       = 2
+	exitTimeout     = 3
+	exitHTTPError   = 4
+	exitDecodeError = 5
+	exitStoreError  = 6
+	// Falhas do servidor classificadas pelo status da resposta
+	exitUpstreamTimeout = 7
+	exitUpstreamError   = 8
+	exitUnavailable     = 9
 )
 
 type Quotation struct {
-	Dollar string `json:"price"`
+	Pair      string              `json:"pair"`
+	Name      string              `json:"name"`
+	Bid       decimal.Decimal     `json:"bid"`
+	Ask       decimal.NullDecimal `json:"ask"`
+	High      decimal.NullDecimal `json:"high"`
+	Low       decimal.NullDecimal `json:"low"`
+	Timestamp string              `json:"timestamp"`
+	Provider  string              `json:"provider"`
+	Stale     bool                `json:"stale"`
+	Age       int64               `json:"age"`
+}
+
+type Config struct {
+	ServerURL string
+	GRPCAddr  string
+	Pair      string
+	APIKey    string
+	Timeout   time.Duration
+	Format    string
+	Output    string
+	Append    bool
+	Follow    bool
 }
 
 func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+// Executa o cliente com os argumentos da linha de comando e devolve o código de saída
+func run(args []string) int {
+	config, err := parseFlags(args)
+	if err != nil {
+		log.Println(err)
+		return exitUsage
+	}
+	return execute(config)
+}
+
+func parseFlags(args []string) (Config, error) {
+	var config Config
+	fs := flag.NewFlagSet("client", flag.ContinueOnError)
+	fs.StringVar(&config.ServerURL, "url", "http://127.0.0.1:8080", "quotation server URL")
+	fs.StringVar(&config.GRPCAddr, "grpc", "", "use the gRPC QuoteService at this address (e.g. 127.0.0.1:9090) instead of HTTP")
+	fs.StringVar(&config.Pair, "pair", "USD-BRL", "currency pair, e.g. EUR-BRL")
+	fs.StringVar(&config.APIKey, "key", os.Getenv("QUOTE_API_KEY"), "API key sent as a Bearer token (default $QUOTE_API_KEY)")
+	fs.DurationVar(&config.Timeout, "timeout", 300*time.Millisecond, "maximum time to wait for the server")
+	fs.StringVar(&config.Format, "format", "text", "output format: text, json or csv")
+	fs.StringVar(&config.Output, "output", "", `output file, "-" for stdout (default cotacao.txt, cotacao.json or cotacao.csv)`)
+	fs.BoolVar(&config.Append, "append", false, "append a dated entry to the output file instead of overwriting it")
+	fs.BoolVar(&config.Follow, "follow", false, "keep the connection open and store every new quotation pushed by the server")
+	if err := fs.Parse(args); err != nil {
+		return config, err
+	}
+	if _, ok := formats[config.Format]; !ok {
+		return config, errors.New("invalid format: " + config.Format)
+	}
+	if config.Follow && config.GRPCAddr != "" {
+		return config, errors.New("-follow is not supported with -grpc")
+	}
+	if config.Output == "" {
+		config.Output = "cotacao." + formats[config.Format].extension
+	}
+	config.Pair = strings.ToUpper(config.Pair)
+	return config, nil
+}
+
+func execute(config Config) int {
 	log.Default().Println("Starting...")
-	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	if config.Follow {
+		return follow(config)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
 	defer cancel()
-	req, err := http.NewRequestWithContext(ctx, "GET", "http://127.0.0.1:8080/usd-to-brl", nil)
+	fetch := fetchQuotation
+	if config.GRPCAddr != "" {
+		fetch = fetchQuotationGRPC
+	}
+	q, code := fetch(ctx, config)
+	if code != exitOK {
+		return code
+	}
+	err := Store(config, q, time.Now())
+	if err != nil {
+		log.Println("error storing quotation: " + err.Error())
+		return exitStoreError
+	}
+	return exitOK
+}
+
+func fetchQuotation(ctx context.Context, config Config) (*Quotation, int) {
+	endpoint := strings.TrimSuffix(config.ServerURL, "/") + "/quotes/" + url.PathEscape(config.Pair)
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
 	if err != nil {
-		log.Fatal("error creating request")
-		return
+		log.Println("error creating request")
+		return nil, exitError
 	}
+	authorize(req, config)
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		log.Fatal("error executing request")
-		return
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			log.Println("Request Timeout " + config.ServerURL)
+			return nil, exitTimeout
+		}
+		log.Println("error executing request: " + err.Error())
+		return nil, exitHTTPError
 	}
 	defer resp.Body.Close()
 	res, err := io.ReadAll(resp.Body)
 	if err != nil {
-		log.Fatal("error reading response")
-		return
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			log.Println("Request Timeout " + config.ServerURL)
+			return nil, exitTimeout
+		}
+		log.Println("error reading response")
+		return nil, exitHTTPError
 	}
 	if resp.StatusCode != http.StatusOK {
-		log.Printf("StatusCode: %v \n", resp.StatusCode)
-		log.Println("Response received: " + string(res))
-		return
+		return nil, problemExitCode(resp, res)
 	}
 	var q Quotation
 	log.Println("Response received:", string(res))
 	err = json.Unmarshal(res, &q)
-	if err != nil {
-		log.Fatal("error unmarshalling response")
-		return
+	if err != nil || q.Bid.Sign() <= 0 {
+		log.Println("error unmarshalling response")
+		return nil, exitDecodeError
 	}
-	err = StoreInTxt(&q)
-	if err != nil {
-		log.Fatal("error storing in txt")
-		return
+	return &q, exitOK
+}
+
+func authorize(req *http.Request, config Config) {
+	if config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+config.APIKey)
 	}
 }
 
-func StoreInTxt(q *Quotation) error {
-	file, err := os.Create("cotacao.txt")
-	if err != nil {
-		return errors.New("error creating file")
+// Rótulo usado no formato texto; o dólar mantém o formato original do desafio
+func label(pair string) string {
+	if pair == "USD-BRL" {
+		return "Dólar"
 	}
-	defer file.Close()
-	_, err = file.WriteString("Dólar: " + q.Dollar + "\n")
-	if err != nil {
-		return errors.New("error writing to file: " + err.Error())
+	return pair
+}
+
+func describe(q *Quotation) string {
+	s := fmt.Sprintf("%s: %s", label(q.Pair), q.Bid.String())
+	if q.Stale {
+		s += fmt.Sprintf(" (stale, %ds)", q.Age)
 	}
-	return nil
+	return s
 }
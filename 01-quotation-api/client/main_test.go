@@ -0,0 +1,94 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const quotationJSON = `{"pair":"USD-BRL","name":"Dólar Americano/Real Brasileiro","bid":"5.1234","timestamp":"1718000000","provider":"awesomeapi"}`
+
+func TestRunExitCodes(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		code    int
+	}{
+		{"cotação gravada", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(quotationJSON))
+		}, exitOK},
+		{"servidor estoura o prazo", func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}, exitTimeout},
+		{"erro HTTP", func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "internal error", http.StatusInternalServerError)
+		}, exitHTTPError},
+		{"provedor lento", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.WriteHeader(http.StatusGatewayTimeout)
+			w.Write([]byte(`{"title":"Gateway Timeout","status":504,"detail":"upstream timeout"}`))
+		}, exitUpstreamTimeout},
+		{"provedor com erro", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}, exitUpstreamError},
+		{"banco indisponível", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}, exitUnavailable},
+		{"resposta inválida", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"bid":`))
+		}, exitDecodeError},
+		{"bid zerado", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"pair":"USD-BRL","bid":"0"}`))
+		}, exitDecodeError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+			output := filepath.Join(t.TempDir(), "cotacao.json")
+
+			code := run([]string{"-url", server.URL, "-format", "json", "-output", output, "-timeout", "100ms"})
+			if code != tt.code {
+				t.Fatalf("código de saída %d, esperado %d", code, tt.code)
+			}
+			_, err := os.Stat(output)
+			if (tt.code == exitOK) != (err == nil) {
+				t.Errorf("arquivo gravado só deveria existir com sucesso: %v", err)
+			}
+		})
+	}
+}
+
+func TestRunServerDown(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	code := run([]string{"-url", url, "-output", filepath.Join(t.TempDir(), "cotacao.txt")})
+	if code != exitHTTPError {
+		t.Errorf("código de saída %d, esperado %d", code, exitHTTPError)
+	}
+}
+
+func TestRunStoreError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(quotationJSON))
+	}))
+	defer server.Close()
+
+	output := filepath.Join(t.TempDir(), "missing", "cotacao.txt")
+	if code := run([]string{"-url", server.URL, "-output", output}); code != exitStoreError {
+		t.Errorf("código de saída %d, esperado %d", code, exitStoreError)
+	}
+}
+
+func TestRunUsage(t *testing.T) {
+	for _, args := range [][]string{{"-format", "xml"}, {"-follow", "-grpc", "127.0.0.1:9090"}, {"-unknown"}} {
+		if code := run(args); code != exitUsage {
+			t.Errorf("%s: código de saída %d, esperado %d", strings.Join(args, " "), code, exitUsage)
+		}
+	}
+}
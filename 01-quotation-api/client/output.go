@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+type format struct {
+	extension string
+	header    func(w io.Writer) error
+	write     func(w io.Writer, q *Quotation, at time.Time, dated bool) error
+}
+
+var formats = map[string]format{
+	"text": {extension: "txt", write: writeText},
+	"json": {extension: "json", write: writeJSON},
+	"csv":  {extension: "csv", header: writeCSVHeader, write: writeCSV},
+}
+
+// No modo -follow Store é chamado a cada cotação; o cabeçalho só sai uma vez no stdout
+var stdoutHeaderWritten bool
+
+// Grava a cotação no arquivo configurado. Com -append a cotação é
+// adicionada ao final, com a data da consulta, formando um histórico.
+func Store(config Config, q *Quotation, at time.Time) error {
+	f := formats[config.Format]
+	if config.Output == "-" {
+		if f.header != nil && !stdoutHeaderWritten {
+			if err := f.header(os.Stdout); err != nil {
+				return err
+			}
+			stdoutHeaderWritten = true
+		}
+		return f.write(os.Stdout, q, at, config.Append)
+	}
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if config.Append {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	file, err := os.OpenFile(config.Output, flags, 0644)
+	if err != nil {
+		return errors.New("error creating file: " + err.Error())
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return errors.New("error reading file: " + err.Error())
+	}
+	if f.header != nil && info.Size() == 0 {
+		if err = f.header(file); err != nil {
+			return errors.New("error writing to file: " + err.Error())
+		}
+	}
+	if err = f.write(file, q, at, config.Append); err != nil {
+		return errors.New("error writing to file: " + err.Error())
+	}
+	return nil
+}
+
+func writeText(w io.Writer, q *Quotation, at time.Time, dated bool) error {
+	line := describe(q) + "\n"
+	if dated {
+		line = at.Format(time.RFC3339) + " " + line
+	}
+	_, err := io.WriteString(w, line)
+	return err
+}
+
+type jsonEntry struct {
+	FetchedAt string `json:"fetched_at"`
+	*Quotation
+}
+
+// Um objeto por linha (JSON Lines), para que o modo -append continue válido
+func writeJSON(w io.Writer, q *Quotation, at time.Time, dated bool) error {
+	return json.NewEncoder(w).Encode(jsonEntry{FetchedAt: at.Format(time.RFC3339), Quotation: q})
+}
+
+func writeCSVHeader(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"fetched_at", "pair", "bid", "ask", "high", "low", "timestamp", "provider", "stale", "age"})
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeCSV(w io.Writer, q *Quotation, at time.Time, dated bool) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{
+		at.Format(time.RFC3339), q.Pair, q.Bid.String(), q.Ask.String(), q.High.String(), q.Low.String(), q.Timestamp, q.Provider,
+		strconv.FormatBool(q.Stale), strconv.FormatInt(q.Age, 10),
+	})
+	cw.Flush()
+	return cw.Error()
+}
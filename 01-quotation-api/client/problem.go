@@ -0,0 +1,42 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"mime"
+	"net/http"
+)
+
+// Corpo de erro do servidor (RFC 7807)
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail"`
+	Provider string `json:"provider"`
+	Stage    string `json:"stage"`
+}
+
+// Registra a falha do servidor e escolhe o código de saída pelo status: um
+// provedor lento (504) ou com erro (502) e o banco indisponível (503) têm
+// códigos próprios para que scripts decidam se vale tentar de novo.
+func problemExitCode(resp *http.Response, body []byte) int {
+	log.Printf("StatusCode: %v \n", resp.StatusCode)
+	var p Problem
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == "application/problem+json" && json.Unmarshal(body, &p) == nil {
+		log.Printf("%s: %s (%s)\n", p.Title, p.Detail, p.Type)
+	} else {
+		log.Println("Response received: " + string(body))
+	}
+	switch resp.StatusCode {
+	case http.StatusGatewayTimeout:
+		return exitUpstreamTimeout
+	case http.StatusBadGateway:
+		return exitUpstreamError
+	case http.StatusServiceUnavailable:
+		return exitUnavailable
+	default:
+		return exitHTTPError
+	}
+}
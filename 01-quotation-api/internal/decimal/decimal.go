@@ -0,0 +1,220 @@
+// Package decimal implementa um decimal de ponto fixo para preços de cotações.
+//
+// O valor é guardado como um inteiro de unidades de 10^-8 (Scale casas
+// decimais), o que é suficiente para cotações de criptomoedas e mantém os
+// preços exatos entre o provedor, o SQLite, o JSON e o arquivo do cliente.
+package decimal
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Casas decimais representadas
+const Scale = 8
+
+const unitsPerOne = 100_000_000
+
+var (
+	ErrInvalid   = errors.New("decimal: invalid number")
+	ErrPrecision = errors.New("decimal: too many decimal places")
+	ErrRange     = errors.New("decimal: value out of range")
+)
+
+type Decimal struct {
+	units int64
+}
+
+func FromUnits(units int64) Decimal {
+	return Decimal{units: units}
+}
+
+// Aceita apenas a notação decimal simples: sinal opcional, dígitos e ponto
+// opcional seguido de até Scale dígitos (zeros à direita são ignorados).
+func Parse(s string) (Decimal, error) {
+	negative := false
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		negative = s[0] == '-'
+		s = s[1:]
+	}
+	intPart, fracPart, hasDot := strings.Cut(s, ".")
+	if intPart == "" || (hasDot && fracPart == "") || !isDigits(intPart) || !isDigits(fracPart) {
+		return Decimal{}, ErrInvalid
+	}
+	fracPart = strings.TrimRight(fracPart, "0")
+	if len(fracPart) > Scale {
+		return Decimal{}, ErrPrecision
+	}
+	whole, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil {
+		return Decimal{}, ErrRange
+	}
+	var frac int64
+	if fracPart != "" {
+		frac, _ = strconv.ParseInt(fracPart+strings.Repeat("0", Scale-len(fracPart)), 10, 64)
+	}
+	if whole > (math.MaxInt64-frac)/unitsPerOne {
+		return Decimal{}, ErrRange
+	}
+	units := whole*unitsPerOne + frac
+	if negative {
+		units = -units
+	}
+	return Decimal{units: units}, nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func (d Decimal) Units() int64 {
+	return d.units
+}
+
+func (d Decimal) Sign() int {
+	switch {
+	case d.units < 0:
+		return -1
+	case d.units > 0:
+		return 1
+	}
+	return 0
+}
+
+func (d Decimal) Cmp(other Decimal) int {
+	switch {
+	case d.units < other.units:
+		return -1
+	case d.units > other.units:
+		return 1
+	}
+	return 0
+}
+
+func (d Decimal) Rat() *big.Rat {
+	return big.NewRat(d.units, unitsPerOne)
+}
+
+// Aproximação em ponto flutuante, apenas para estatísticas
+func (d Decimal) Float64() float64 {
+	return float64(d.units) / unitsPerOne
+}
+
+// Representação canônica, sem zeros à direita: 5.1230 -> "5.123"
+func (d Decimal) String() string {
+	sign := ""
+	units := uint64(d.units)
+	if d.units < 0 {
+		sign = "-"
+		units = uint64(-d.units)
+	}
+	whole := units / unitsPerOne
+	frac := units % unitsPerOne
+	if frac == 0 {
+		return fmt.Sprintf("%s%d", sign, whole)
+	}
+	fracPart := strings.TrimRight(fmt.Sprintf("%08d", frac), "0")
+	return fmt.Sprintf("%s%d.%s", sign, whole, fracPart)
+}
+
+// No JSON o decimal é uma string, como na awesomeapi, para não passar por float
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// Aceita tanto strings ("5.1234") quanto números (5.1234)
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if strings.HasPrefix(s, `"`) {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return ErrInvalid
+		}
+	}
+	parsed, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// No banco o decimal é guardado em unidades inteiras de 10^-8
+func (d Decimal) Value() (driver.Value, error) {
+	return d.units, nil
+}
+
+func (d *Decimal) Scan(src any) error {
+	switch v := src.(type) {
+	case int64:
+		d.units = v
+		return nil
+	case string:
+		return d.UnmarshalJSON([]byte(v))
+	case []byte:
+		return d.UnmarshalJSON(v)
+	}
+	return fmt.Errorf("decimal: cannot scan %T", src)
+}
+
+// Decimal opcional, para preços que nem todo provedor informa
+type NullDecimal struct {
+	Decimal Decimal
+	Valid   bool
+}
+
+func (n NullDecimal) String() string {
+	if !n.Valid {
+		return ""
+	}
+	return n.Decimal.String()
+}
+
+func (n NullDecimal) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+	return n.Decimal.MarshalJSON()
+}
+
+// null e "" são tratados como ausentes
+func (n *NullDecimal) UnmarshalJSON(data []byte) error {
+	if s := string(data); s == "null" || s == `""` {
+		*n = NullDecimal{}
+		return nil
+	}
+	if err := n.Decimal.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+func (n NullDecimal) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.Decimal.Value()
+}
+
+func (n *NullDecimal) Scan(src any) error {
+	if src == nil {
+		*n = NullDecimal{}
+		return nil
+	}
+	if err := n.Decimal.Scan(src); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
@@ -0,0 +1,97 @@
+package decimal
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		units int64
+		str   string
+	}{
+		{"5.1234", 512340000, "5.1234"},
+		{"5.12340", 512340000, "5.1234"},
+		{"0.00000001", 1, "0.00000001"},
+		{"350123.12345678", 35012312345678, "350123.12345678"},
+		{"-1.5", -150000000, "-1.5"},
+		{"+2", 200000000, "2"},
+		{"0", 0, "0"},
+		{"7.000000000", 700000000, "7"},
+	}
+	for _, tt := range tests {
+		d, err := Parse(tt.input)
+		if err != nil {
+			t.Errorf("Parse(%q): erro inesperado %v", tt.input, err)
+			continue
+		}
+		if d.Units() != tt.units {
+			t.Errorf("Parse(%q): unidades %d, esperado %d", tt.input, d.Units(), tt.units)
+		}
+		if d.String() != tt.str {
+			t.Errorf("Parse(%q).String() = %q, esperado %q", tt.input, d.String(), tt.str)
+		}
+	}
+}
+
+func TestParseRejectsInvalidValues(t *testing.T) {
+	tests := map[string]error{
+		"":                     ErrInvalid,
+		"abc":                  ErrInvalid,
+		"5,12":                 ErrInvalid,
+		"1e5":                  ErrInvalid,
+		"NaN":                  ErrInvalid,
+		".5":                   ErrInvalid,
+		"5.":                   ErrInvalid,
+		" 5":                   ErrInvalid,
+		"0.000000001":          ErrPrecision,
+		"99999999999999999999": ErrRange,
+		"92233720368.54775808": ErrRange,
+	}
+	for input, want := range tests {
+		if _, err := Parse(input); err != want {
+			t.Errorf("Parse(%q): erro %v, esperado %v", input, err, want)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	var quote struct {
+		Bid  Decimal     `json:"bid"`
+		Ask  Decimal     `json:"ask"`
+		High NullDecimal `json:"high"`
+		Low  NullDecimal `json:"low"`
+	}
+	err := json.Unmarshal([]byte(`{"bid":"5.1230","ask":5.1240,"high":"","low":null}`), &quote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quote.Bid.String() != "5.123" || quote.Ask.String() != "5.124" || quote.High.Valid || quote.Low.Valid {
+		t.Errorf("decodificação incorreta: %+v", quote)
+	}
+	out, err := json.Marshal(quote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != `{"bid":"5.123","ask":"5.124","high":null,"low":null}` {
+		t.Errorf("codificação incorreta: %s", out)
+	}
+	if err := json.Unmarshal([]byte(`{"bid":"abc"}`), &quote); err != ErrInvalid {
+		t.Errorf("erro %v, esperado %v", err, ErrInvalid)
+	}
+}
+
+func TestScan(t *testing.T) {
+	var d Decimal
+	if err := d.Scan(int64(512340000)); err != nil || d.String() != "5.1234" {
+		t.Errorf("Scan(int64): %v %s", err, d)
+	}
+	var n NullDecimal
+	if err := n.Scan(nil); err != nil || n.Valid {
+		t.Errorf("Scan(nil): %v %+v", err, n)
+	}
+	if v, _ := n.Value(); v != nil {
+		t.Errorf("Value() de NullDecimal inválido: %v", v)
+	}
+}
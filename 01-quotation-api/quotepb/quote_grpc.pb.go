@@ -0,0 +1,225 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.4.0
+// - protoc             v27.3.0
+// source: quote.proto
+
+// API tipada do servidor de cotações, servida ao lado da API HTTP.
+// Preços são strings decimais exatas ("5.1234"), como no JSON.
+
+package quotepb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.62.0 or later.
+const _ = grpc.SupportPackageIsVersion8
+
+const (
+	QuoteService_GetQuote_FullMethodName    = "/quotation.v1.QuoteService/GetQuote"
+	QuoteService_ListHistory_FullMethodName = "/quotation.v1.QuoteService/ListHistory"
+	QuoteService_WatchQuotes_FullMethodName = "/quotation.v1.QuoteService/WatchQuotes"
+)
+
+// QuoteServiceClient is the client API for QuoteService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type QuoteServiceClient interface {
+	// Cotação atual do par, com as mesmas regras de cache do GET /quotes/{pair}
+	GetQuote(ctx context.Context, in *GetQuoteRequest, opts ...grpc.CallOption) (*Quote, error)
+	// Cotações registradas, em ordem de inserção, paginadas por cursor
+	ListHistory(ctx context.Context, in *ListHistoryRequest, opts ...grpc.CallOption) (*ListHistoryResponse, error)
+	// Cotações conforme são registradas. Com after_id, as registradas depois
+	// daquele id são reenviadas antes das novas.
+	WatchQuotes(ctx context.Context, in *WatchQuotesRequest, opts ...grpc.CallOption) (QuoteService_WatchQuotesClient, error)
+}
+
+type quoteServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewQuoteServiceClient(cc grpc.ClientConnInterface) QuoteServiceClient {
+	return &quoteServiceClient{cc}
+}
+
+func (c *quoteServiceClient) GetQuote(ctx context.Context, in *GetQuoteRequest, opts ...grpc.CallOption) (*Quote, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Quote)
+	err := c.cc.Invoke(ctx, QuoteService_GetQuote_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *quoteServiceClient) ListHistory(ctx context.Context, in *ListHistoryRequest, opts ...grpc.CallOption) (*ListHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListHistoryResponse)
+	err := c.cc.Invoke(ctx, QuoteService_ListHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *quoteServiceClient) WatchQuotes(ctx context.Context, in *WatchQuotesRequest, opts ...grpc.CallOption) (QuoteService_WatchQuotesClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &QuoteService_ServiceDesc.Streams[0], QuoteService_WatchQuotes_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &quoteServiceWatchQuotesClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type QuoteService_WatchQuotesClient interface {
+	Recv() (*StoredQuote, error)
+	grpc.ClientStream
+}
+
+type quoteServiceWatchQuotesClient struct {
+	grpc.ClientStream
+}
+
+func (x *quoteServiceWatchQuotesClient) Recv() (*StoredQuote, error) {
+	m := new(StoredQuote)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// QuoteServiceServer is the server API for QuoteService service.
+// All implementations must embed UnimplementedQuoteServiceServer
+// for forward compatibility
+type QuoteServiceServer interface {
+	// Cotação atual do par, com as mesmas regras de cache do GET /quotes/{pair}
+	GetQuote(context.Context, *GetQuoteRequest) (*Quote, error)
+	// Cotações registradas, em ordem de inserção, paginadas por cursor
+	ListHistory(context.Context, *ListHistoryRequest) (*ListHistoryResponse, error)
+	// Cotações conforme são registradas. Com after_id, as registradas depois
+	// daquele id são reenviadas antes das novas.
+	WatchQuotes(*WatchQuotesRequest, QuoteService_WatchQuotesServer) error
+	mustEmbedUnimplementedQuoteServiceServer()
+}
+
+// UnimplementedQuoteServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedQuoteServiceServer struct {
+}
+
+func (UnimplementedQuoteServiceServer) GetQuote(context.Context, *GetQuoteRequest) (*Quote, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetQuote not implemented")
+}
+func (UnimplementedQuoteServiceServer) ListHistory(context.Context, *ListHistoryRequest) (*ListHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListHistory not implemented")
+}
+func (UnimplementedQuoteServiceServer) WatchQuotes(*WatchQuotesRequest, QuoteService_WatchQuotesServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchQuotes not implemented")
+}
+func (UnimplementedQuoteServiceServer) mustEmbedUnimplementedQuoteServiceServer() {}
+
+// UnsafeQuoteServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to QuoteServiceServer will
+// result in compilation errors.
+type UnsafeQuoteServiceServer interface {
+	mustEmbedUnimplementedQuoteServiceServer()
+}
+
+func RegisterQuoteServiceServer(s grpc.ServiceRegistrar, srv QuoteServiceServer) {
+	s.RegisterService(&QuoteService_ServiceDesc, srv)
+}
+
+func _QuoteService_GetQuote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetQuoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuoteServiceServer).GetQuote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: QuoteService_GetQuote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuoteServiceServer).GetQuote(ctx, req.(*GetQuoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QuoteService_ListHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuoteServiceServer).ListHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: QuoteService_ListHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuoteServiceServer).ListHistory(ctx, req.(*ListHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QuoteService_WatchQuotes_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchQuotesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(QuoteServiceServer).WatchQuotes(m, &quoteServiceWatchQuotesServer{ServerStream: stream})
+}
+
+type QuoteService_WatchQuotesServer interface {
+	Send(*StoredQuote) error
+	grpc.ServerStream
+}
+
+type quoteServiceWatchQuotesServer struct {
+	grpc.ServerStream
+}
+
+func (x *quoteServiceWatchQuotesServer) Send(m *StoredQuote) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// QuoteService_ServiceDesc is the grpc.ServiceDesc for QuoteService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var QuoteService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "quotation.v1.QuoteService",
+	HandlerType: (*QuoteServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetQuote",
+			Handler:    _QuoteService_GetQuote_Handler,
+		},
+		{
+			MethodName: "ListHistory",
+			Handler:    _QuoteService_ListHistory_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchQuotes",
+			Handler:       _QuoteService_WatchQuotes_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "quote.proto",
+}
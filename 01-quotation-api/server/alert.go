@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/isaacmirandacampos/go-expert/quotation-api/internal/decimal"
+)
+
+const (
+	AlertAbove  = "above"
+	AlertBelow  = "below"
+	AlertChange = "change"
+)
+
+var errAlertNotFound = errors.New("alert not found")
+
+type Alert struct {
+	ID              int64
+	Pair            string
+	Kind            string
+	Threshold       decimal.NullDecimal
+	Percent         decimal.NullDecimal
+	Window          time.Duration
+	URL             string
+	Secret          string
+	Armed           bool
+	CreatedAt       time.Time
+	LastTriggeredAt sql.NullInt64
+}
+
+func (a *Alert) validate() error {
+	if _, err := parsePair(a.Pair); err != nil {
+		return err
+	}
+	u, err := url.Parse(a.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("invalid url")
+	}
+	switch a.Kind {
+	case AlertAbove, AlertBelow:
+		if !a.Threshold.Valid || a.Threshold.Decimal.Sign() <= 0 {
+			return errors.New("threshold must be a positive number")
+		}
+	case AlertChange:
+		if !a.Percent.Valid || a.Percent.Decimal.Sign() <= 0 {
+			return errors.New("percent must be a positive number")
+		}
+		if a.Window <= 0 {
+			return errors.New("window must be a positive duration")
+		}
+	default:
+		return errors.New("kind must be above, below or change")
+	}
+	return nil
+}
+
+func newAlertSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.New("error generating secret")
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func (s *Store) CreateAlert(a *Alert) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Query)
+	defer cancel()
+
+	a.Armed = true
+	a.CreatedAt = time.Now()
+	result, err := s.db.ExecContext(ctx,
+		`INSERT INTO alerts (pair, kind, threshold, percent, window_seconds, url, secret, armed, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, 1, ?)`,
+		a.Pair, a.Kind, a.Threshold, a.Percent, int64(a.Window.Seconds()), a.URL, a.Secret, a.CreatedAt.Unix(),
+	)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			log.Println("Insert Timeout")
+		}
+		return databaseError(ctx, "exec", err)
+	}
+	a.ID, _ = result.LastInsertId()
+	return nil
+}
+
+const alertColumns = `id, pair, kind, threshold, percent, window_seconds, url, secret, armed, created_at, last_triggered_at`
+
+func scanAlert(row interface{ Scan(...any) error }) (Alert, error) {
+	var a Alert
+	var windowSeconds sql.NullInt64
+	var createdAt int64
+	err := row.Scan(&a.ID, &a.Pair, &a.Kind, &a.Threshold, &a.Percent, &windowSeconds, &a.URL, &a.Secret, &a.Armed, &createdAt, &a.LastTriggeredAt)
+	a.Window = time.Duration(windowSeconds.Int64) * time.Second
+	a.CreatedAt = time.Unix(createdAt, 0)
+	return a, err
+}
+
+// Lista os alertas do par, ou todos quando pair é vazio
+func (s *Store) ListAlerts(pair string) ([]Alert, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Query)
+	defer cancel()
+
+	query := `SELECT ` + alertColumns + ` FROM alerts`
+	args := []any{}
+	if pair != "" {
+		query += ` WHERE pair = ?`
+		args = append(args, pair)
+	}
+	rows, err := s.db.QueryContext(ctx, query+` ORDER BY id`, args...)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			log.Println("Query Timeout")
+		}
+		return nil, databaseError(ctx, "query", err)
+	}
+	defer rows.Close()
+
+	alerts := []Alert{}
+	for rows.Next() {
+		a, err := scanAlert(rows)
+		if err != nil {
+			return nil, databaseError(ctx, "scan", err)
+		}
+		alerts = append(alerts, a)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, databaseError(ctx, "query", err)
+	}
+	return alerts, nil
+}
+
+func (s *Store) GetAlert(id int64) (*Alert, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Query)
+	defer cancel()
+
+	a, err := scanAlert(s.db.QueryRowContext(ctx, `SELECT `+alertColumns+` FROM alerts WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, errAlertNotFound
+	}
+	if err != nil {
+		return nil, databaseError(ctx, "query", err)
+	}
+	return &a, nil
+}
+
+func (s *Store) DeleteAlert(id int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Query)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM alerts WHERE id = ?`, id)
+	if err != nil {
+		return databaseError(ctx, "exec", err)
+	}
+	if count, _ := result.RowsAffected(); count == 0 {
+		return errAlertNotFound
+	}
+	return nil
+}
+
+// Desarma o alerta quando dispara (registrando o momento) e rearma quando a condição deixa de valer
+func (s *Store) SetAlertArmed(id int64, armed bool, triggeredAt time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Query)
+	defer cancel()
+
+	var err error
+	if armed {
+		_, err = s.db.ExecContext(ctx, `UPDATE alerts SET armed = 1 WHERE id = ?`, id)
+	} else {
+		_, err = s.db.ExecContext(ctx, `UPDATE alerts SET armed = 0, last_triggered_at = ? WHERE id = ?`, triggeredAt.Unix(), id)
+	}
+	if err != nil {
+		return databaseError(ctx, "exec", err)
+	}
+	return nil
+}
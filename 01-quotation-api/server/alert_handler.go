@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/isaacmirandacampos/go-expert/quotation-api/internal/decimal"
+)
+
+type AlertRequest struct {
+	Pair      string              `json:"pair"`
+	Kind      string              `json:"kind"`
+	Threshold decimal.NullDecimal `json:"threshold"`
+	Percent   decimal.NullDecimal `json:"percent"`
+	Window    string              `json:"window"`
+	URL       string              `json:"url"`
+	Secret    string              `json:"secret"`
+}
+
+type AlertResponse struct {
+	ID              int64               `json:"id"`
+	Pair            string              `json:"pair"`
+	Kind            string              `json:"kind"`
+	Threshold       decimal.NullDecimal `json:"threshold"`
+	Percent         decimal.NullDecimal `json:"percent"`
+	Window          string              `json:"window,omitempty"`
+	URL             string              `json:"url"`
+	Secret          string              `json:"secret,omitempty"`
+	Armed           bool                `json:"armed"`
+	CreatedAt       string              `json:"created_at"`
+	LastTriggeredAt string              `json:"last_triggered_at,omitempty"`
+}
+
+// O segredo só é devolvido na criação
+func newAlertResponse(a *Alert, withSecret bool) AlertResponse {
+	response := AlertResponse{
+		ID:              a.ID,
+		Pair:            a.Pair,
+		Kind:            a.Kind,
+		Threshold:       a.Threshold,
+		Percent:         a.Percent,
+		URL:             a.URL,
+		Armed:           a.Armed,
+		CreatedAt:       a.CreatedAt.UTC().Format(time.RFC3339),
+		LastTriggeredAt: formatUnix(a.LastTriggeredAt),
+	}
+	if a.Window > 0 {
+		response.Window = a.Window.String()
+	}
+	if withSecret {
+		response.Secret = a.Secret
+	}
+	return response
+}
+
+func (s *Server) createAlertHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var request AlertRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, "invalid body: "+err.Error())
+		return
+	}
+	pair, err := parsePair(request.Pair)
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	alert := Alert{
+		Pair:      pair.String(),
+		Kind:      request.Kind,
+		Threshold: request.Threshold,
+		Percent:   request.Percent,
+		URL:       request.URL,
+		Secret:    request.Secret,
+	}
+	if request.Window != "" {
+		alert.Window, err = time.ParseDuration(request.Window)
+		if err != nil {
+			writeProblem(w, r, http.StatusBadRequest, "invalid window")
+			return
+		}
+	}
+	if err = alert.validate(); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if alert.Secret == "" {
+		alert.Secret, err = newAlertSecret()
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+	}
+	if err = s.store.CreateAlert(&alert); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(newAlertResponse(&alert, true))
+}
+
+func (s *Server) listAlertsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	pair := ""
+	if value := r.URL.Query().Get("pair"); value != "" {
+		p, err := parsePair(value)
+		if err != nil {
+			writeProblem(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		pair = p.String()
+	}
+	alerts, err := s.store.ListAlerts(pair)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	response := make([]AlertResponse, 0, len(alerts))
+	for i := range alerts {
+		response = append(response, newAlertResponse(&alerts[i], false))
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (s *Server) getAlertHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, "invalid id")
+		return
+	}
+	alert, err := s.store.GetAlert(id)
+	if errors.Is(err, errAlertNotFound) {
+		writeProblem(w, r, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	json.NewEncoder(w).Encode(newAlertResponse(alert, false))
+}
+
+func (s *Server) deleteAlertHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, "invalid id")
+		return
+	}
+	err = s.store.DeleteAlert(id)
+	if errors.Is(err, errAlertNotFound) {
+		writeProblem(w, r, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"math/big"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/isaacmirandacampos/go-expert/quotation-api/internal/decimal"
+)
+
+const (
+	alertQueueSize        = 256
+	webhookAttempts       = 5
+	webhookBackoff        = 1 * time.Second
+	webhookMaxBackoff     = 1 * time.Minute
+	webhookAttemptTimeout = 5 * time.Second
+)
+
+type AlertEvent struct {
+	AlertID      int64               `json:"alert_id"`
+	Pair         string              `json:"pair"`
+	Kind         string              `json:"kind"`
+	Threshold    decimal.NullDecimal `json:"threshold"`
+	Percent      decimal.NullDecimal `json:"percent"`
+	Window       string              `json:"window,omitempty"`
+	Bid          decimal.Decimal     `json:"bid"`
+	ReferenceBid decimal.NullDecimal `json:"reference_bid"`
+	Change       string              `json:"change,omitempty"`
+	QuoteID      int64               `json:"quote_id"`
+	QuotedAt     string              `json:"quoted_at"`
+	TriggeredAt  string              `json:"triggered_at"`
+}
+
+// Avalia os alertas a cada cotação registrada e entrega os webhooks dos que
+// dispararem. As cotações entram por uma fila para não atrasar a escrita.
+type AlertEvaluator struct {
+	store  *Store
+	quotes chan StoredQuote
+	// Entregas em andamento, aguardadas no desligamento
+	deliveries sync.WaitGroup
+	// Fechado no desligamento para interromper a espera entre retentativas
+	stopping chan struct{}
+	client   *http.Client
+	attempts int
+	backoff  time.Duration
+}
+
+func NewAlertEvaluator(store *Store) *AlertEvaluator {
+	return &AlertEvaluator{
+		store:    store,
+		quotes:   make(chan StoredQuote, alertQueueSize),
+		stopping: make(chan struct{}),
+		client:   &http.Client{Timeout: webhookAttemptTimeout},
+		attempts: webhookAttempts,
+		backoff:  webhookBackoff,
+	}
+}
+
+func (e *AlertEvaluator) Enqueue(q StoredQuote) {
+	select {
+	case e.quotes <- q:
+	default:
+		log.Printf("Alert queue full, skipping evaluation of %s quote %d\n", q.Pair, q.ID)
+	}
+}
+
+// Ao cancelar o contexto, avalia o que ainda está na fila antes de retornar
+func (e *AlertEvaluator) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			for {
+				select {
+				case q := <-e.quotes:
+					e.evaluate(q)
+				default:
+					return
+				}
+			}
+		case q := <-e.quotes:
+			e.evaluate(q)
+		}
+	}
+}
+
+// Aguarda as entregas em andamento até o fim do contexto; depois disso as
+// retentativas pendentes são abandonadas.
+func (e *AlertEvaluator) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		e.deliveries.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		close(e.stopping)
+		return ctx.Err()
+	}
+}
+
+func (e *AlertEvaluator) evaluate(q StoredQuote) {
+	if !q.Bid.Valid {
+		return
+	}
+	alerts, err := e.store.ListAlerts(q.Pair)
+	if err != nil {
+		log.Printf("Error loading alerts for %s: %v\n", q.Pair, err)
+		return
+	}
+	for _, alert := range alerts {
+		event, holds, err := checkAlert(alert, q, e.store.QuoteAt)
+		if err != nil {
+			log.Printf("Error evaluating alert %d: %v\n", alert.ID, err)
+			continue
+		}
+		switch {
+		case holds && alert.Armed:
+			now := time.Now()
+			if err = e.store.SetAlertArmed(alert.ID, false, now); err != nil {
+				log.Printf("Error disarming alert %d: %v\n", alert.ID, err)
+				continue
+			}
+			event.TriggeredAt = now.UTC().Format(time.RFC3339)
+			e.deliveries.Add(1)
+			go func() {
+				defer e.deliveries.Done()
+				e.deliver(alert, event)
+			}()
+		case !holds && !alert.Armed:
+			if err = e.store.SetAlertArmed(alert.ID, true, time.Time{}); err != nil {
+				log.Printf("Error rearming alert %d: %v\n", alert.ID, err)
+			}
+		}
+	}
+}
+
+// Verifica se a condição do alerta vale para a cotação. Alertas de variação
+// comparam com a última cotação registrada até `window` antes desta.
+func checkAlert(a Alert, q StoredQuote, lookup func(Pair, time.Time) (*StoredQuote, error)) (AlertEvent, bool, error) {
+	bid := q.Bid.Decimal
+	event := AlertEvent{
+		AlertID:   a.ID,
+		Pair:      a.Pair,
+		Kind:      a.Kind,
+		Threshold: a.Threshold,
+		Percent:   a.Percent,
+		Bid:       bid,
+		QuoteID:   q.ID,
+		QuotedAt:  formatUnix(q.InsertedAt),
+	}
+	switch a.Kind {
+	case AlertAbove:
+		return event, bid.Cmp(a.Threshold.Decimal) > 0, nil
+	case AlertBelow:
+		return event, bid.Cmp(a.Threshold.Decimal) < 0, nil
+	case AlertChange:
+		event.Window = a.Window.String()
+		pair, err := parsePair(a.Pair)
+		if err != nil {
+			return event, false, err
+		}
+		ref, err := lookup(pair, time.Unix(q.InsertedAt.Int64, 0).Add(-a.Window))
+		if err == sql.ErrNoRows || (err == nil && (!ref.Bid.Valid || ref.Bid.Decimal.Sign() <= 0)) {
+			return event, false, nil
+		}
+		if err != nil {
+			return event, false, err
+		}
+		event.ReferenceBid = ref.Bid
+		// (bid - ref) / ref * 100
+		change := new(big.Rat).Sub(bid.Rat(), ref.Bid.Decimal.Rat())
+		change.Quo(change, ref.Bid.Decimal.Rat())
+		change.Mul(change, big.NewRat(100, 1))
+		event.Change = change.FloatString(4)
+		return event, new(big.Rat).Abs(change).Cmp(a.Percent.Decimal.Rat()) >= 0, nil
+	}
+	return event, false, errors.New("unknown alert kind " + a.Kind)
+}
+
+// Entrega o webhook com retentativas e backoff exponencial com jitter.
+// Respostas 4xx (exceto 429) não são retentadas.
+func (e *AlertEvaluator) deliver(a Alert, event AlertEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	backoff := e.backoff
+	for attempt := 1; ; attempt++ {
+		retry, err := e.post(a, body)
+		if err == nil {
+			log.Printf("Alert %d delivered to %s\n", a.ID, a.URL)
+			return nil
+		}
+		if !retry || attempt >= e.attempts {
+			log.Printf("Alert %d delivery to %s failed after %d attempt(s): %v\n", a.ID, a.URL, attempt, err)
+			return err
+		}
+		wait := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		log.Printf("Alert %d delivery attempt %d failed: %v, retrying in %s\n", a.ID, attempt, err, wait.Round(time.Millisecond))
+		select {
+		case <-time.After(wait):
+		case <-e.stopping:
+			log.Printf("Alert %d delivery to %s abandoned on shutdown\n", a.ID, a.URL)
+			return err
+		}
+		backoff = min(backoff*2, webhookMaxBackoff)
+	}
+}
+
+func (e *AlertEvaluator) post(a Alert, body []byte) (bool, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req, err := http.NewRequest("POST", a.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, errors.New("error creating request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Alert-Id", strconv.FormatInt(a.ID, 10))
+	req.Header.Set("X-Signature-Timestamp", timestamp)
+	req.Header.Set("X-Signature", "sha256="+signWebhook(a.Secret, timestamp, body))
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, errors.New("unexpected status " + resp.Status)
+}
+
+// HMAC-SHA256 de "<timestamp>.<corpo>" com o segredo do alerta
+func signWebhook(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"database/sql"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/isaacmirandacampos/go-expert/quotation-api/internal/decimal"
+)
+
+func mustDecimal(t *testing.T, s string) decimal.NullDecimal {
+	t.Helper()
+	d, err := decimal.Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return decimal.NullDecimal{Decimal: d, Valid: true}
+}
+
+func TestCheckAlertThreshold(t *testing.T) {
+	quote := StoredQuote{ID: 1, Pair: "USD-BRL", Bid: mustDecimal(t, "5.81")}
+	above := Alert{ID: 1, Pair: "USD-BRL", Kind: AlertAbove, Threshold: mustDecimal(t, "5.80")}
+	below := Alert{ID: 2, Pair: "USD-BRL", Kind: AlertBelow, Threshold: mustDecimal(t, "5.80")}
+
+	if _, holds, err := checkAlert(above, quote, nil); err != nil || !holds {
+		t.Errorf("alerta above deveria disparar: holds=%v err=%v", holds, err)
+	}
+	if _, holds, err := checkAlert(below, quote, nil); err != nil || holds {
+		t.Errorf("alerta below não deveria disparar: holds=%v err=%v", holds, err)
+	}
+}
+
+func TestCheckAlertChange(t *testing.T) {
+	now := time.Now()
+	quote := StoredQuote{ID: 2, Pair: "USD-BRL", Bid: mustDecimal(t, "5.06"), InsertedAt: sql.NullInt64{Int64: now.Unix(), Valid: true}}
+	alert := Alert{ID: 1, Pair: "USD-BRL", Kind: AlertChange, Percent: mustDecimal(t, "1"), Window: time.Hour}
+	var requested time.Time
+	lookup := func(p Pair, at time.Time) (*StoredQuote, error) {
+		requested = at
+		return &StoredQuote{ID: 1, Pair: p.String(), Bid: mustDecimal(t, "5")}, nil
+	}
+
+	event, holds, err := checkAlert(alert, quote, lookup)
+	if err != nil || !holds {
+		t.Fatalf("variação de 1.2%% deveria disparar: holds=%v err=%v", holds, err)
+	}
+	if event.Change != "1.2000" {
+		t.Errorf("variação incorreta: %s", event.Change)
+	}
+	if requested.Unix() != now.Add(-time.Hour).Unix() {
+		t.Errorf("referência buscada em %v, esperado %v", requested, now.Add(-time.Hour))
+	}
+}
+
+func TestDeliverRetriesAndSigns(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		expected := "sha256=" + signWebhook("segredo", r.Header.Get("X-Signature-Timestamp"), body)
+		if r.Header.Get("X-Signature") != expected {
+			t.Errorf("assinatura incorreta: %s", r.Header.Get("X-Signature"))
+		}
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	evaluator := NewAlertEvaluator(nil)
+	evaluator.backoff = time.Millisecond
+	alert := Alert{ID: 1, Pair: "USD-BRL", Kind: AlertAbove, URL: server.URL, Secret: "segredo"}
+	err := evaluator.deliver(alert, AlertEvent{AlertID: 1, Pair: "USD-BRL"})
+	if err != nil {
+		t.Fatalf("entrega deveria ter sucesso: %v", err)
+	}
+	if attempts.Load() != 3 {
+		t.Errorf("tentativas: %d, esperado 3", attempts.Load())
+	}
+}
+
+func TestDeliverDoesNotRetryClientErrors(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer server.Close()
+
+	evaluator := NewAlertEvaluator(nil)
+	evaluator.backoff = time.Millisecond
+	alert := Alert{ID: 1, URL: server.URL, Secret: "segredo"}
+	if err := evaluator.deliver(alert, AlertEvent{AlertID: 1}); err == nil {
+		t.Fatal("entrega deveria falhar")
+	}
+	if attempts.Load() != 1 {
+		t.Errorf("tentativas: %d, esperado 1", attempts.Load())
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	apiKeyPrefix         = "qk_"
+	defaultRatePerMinute = 60
+	defaultBurst         = 10
+)
+
+var errAPIKeyNotFound = errors.New("api key not found")
+
+type APIKey struct {
+	ID            int64
+	Name          string
+	Prefix        string
+	RatePerMinute int
+	Burst         int
+	CreatedAt     time.Time
+	RevokedAt     sql.NullInt64
+}
+
+// Gera uma chave aleatória; só o hash vai para o banco
+func newAPIKey() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.New("error generating api key")
+	}
+	return apiKeyPrefix + hex.EncodeToString(b), nil
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Cria a chave e devolve o seu valor, que não pode ser recuperado depois
+func (s *Store) CreateAPIKey(k *APIKey) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Query)
+	defer cancel()
+
+	key, err := newAPIKey()
+	if err != nil {
+		return "", err
+	}
+	k.Prefix = key[:len(apiKeyPrefix)+6]
+	k.CreatedAt = time.Now()
+	result, err := s.db.ExecContext(ctx,
+		`INSERT INTO api_keys (name, key_hash, prefix, rate_per_minute, burst, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		k.Name, hashAPIKey(key), k.Prefix, k.RatePerMinute, k.Burst, k.CreatedAt.Unix(),
+	)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			log.Println("Insert Timeout")
+		}
+		return "", databaseError(ctx, "exec", err)
+	}
+	k.ID, _ = result.LastInsertId()
+	return key, nil
+}
+
+const apiKeyColumns = `id, name, prefix, rate_per_minute, burst, created_at, revoked_at`
+
+func scanAPIKey(row interface{ Scan(...any) error }) (APIKey, error) {
+	var k APIKey
+	var createdAt int64
+	err := row.Scan(&k.ID, &k.Name, &k.Prefix, &k.RatePerMinute, &k.Burst, &createdAt, &k.RevokedAt)
+	k.CreatedAt = time.Unix(createdAt, 0)
+	return k, err
+}
+
+// Busca uma chave ativa pelo seu valor
+func (s *Store) LookupAPIKey(key string) (*APIKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Query)
+	defer cancel()
+
+	k, err := scanAPIKey(s.db.QueryRowContext(ctx,
+		`SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = ? AND revoked_at IS NULL`, hashAPIKey(key),
+	))
+	if err == sql.ErrNoRows {
+		return nil, errAPIKeyNotFound
+	}
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			log.Println("Query Timeout")
+		}
+		return nil, databaseError(ctx, "query", err)
+	}
+	return &k, nil
+}
+
+func (s *Store) ListAPIKeys() ([]APIKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Query)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys ORDER BY id`)
+	if err != nil {
+		return nil, databaseError(ctx, "query", err)
+	}
+	defer rows.Close()
+
+	keys := []APIKey{}
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, databaseError(ctx, "scan", err)
+		}
+		keys = append(keys, k)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, databaseError(ctx, "query", err)
+	}
+	return keys, nil
+}
+
+func (s *Store) RevokeAPIKey(id int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Query)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, `UPDATE api_keys SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`, time.Now().Unix(), id)
+	if err != nil {
+		return databaseError(ctx, "exec", err)
+	}
+	if count, _ := result.RowsAffected(); count == 0 {
+		return errAPIKeyNotFound
+	}
+	return nil
+}
+
+// Subcomando: server keys [create -name nome [-rate n] [-burst n] | list | revoke id]
+func runKeys(args []string) error {
+	usage := errors.New("usage: server keys [create -name name [-rate n] [-burst n] | list | revoke id]")
+	if len(args) == 0 {
+		return usage
+	}
+	config, err := storeConfigFromEnv()
+	if err != nil {
+		return err
+	}
+	store, err := NewStore(config)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	// Permite criar chaves antes da primeira execução do servidor
+	if _, err = store.Migrate(); err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "create":
+		fs := flag.NewFlagSet("keys create", flag.ContinueOnError)
+		k := APIKey{}
+		fs.StringVar(&k.Name, "name", "", "team or service that owns the key")
+		fs.IntVar(&k.RatePerMinute, "rate", defaultRatePerMinute, "requests per minute")
+		fs.IntVar(&k.Burst, "burst", defaultBurst, "maximum burst of requests")
+		if err = fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if k.Name == "" {
+			return errors.New("name is required")
+		}
+		if k.RatePerMinute <= 0 || k.Burst <= 0 {
+			return errors.New("rate and burst must be positive")
+		}
+		key, err := store.CreateAPIKey(&k)
+		if err != nil {
+			return err
+		}
+		log.Printf("Created api key %d for %s; store it now, it cannot be shown again\n", k.ID, k.Name)
+		fmt.Println(key)
+	case "list":
+		keys, err := store.ListAPIKeys()
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tPREFIX\tRATE/MIN\tBURST\tCREATED\tREVOKED")
+		for _, k := range keys {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%d\t%s\t%s\n", k.ID, k.Name, k.Prefix, k.RatePerMinute, k.Burst, k.CreatedAt.UTC().Format(time.RFC3339), formatUnix(k.RevokedAt))
+		}
+		return w.Flush()
+	case "revoke":
+		if len(args) != 2 {
+			return usage
+		}
+		id, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return errors.New("invalid id: " + args[1])
+		}
+		if err = store.RevokeAPIKey(id); err != nil {
+			return err
+		}
+		log.Printf("Revoked api key %d\n", id)
+	default:
+		return usage
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Buckets sem uso por mais que isso são descartados da memória
+const rateLimiterIdle = 10 * time.Minute
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Token bucket por chave: cada chave acumula rate/60 tokens por segundo, até
+// burst, e cada requisição consome um token.
+type RateLimiter struct {
+	mu      sync.Mutex
+	buckets map[int64]*tokenBucket
+	swept   time.Time
+}
+
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{buckets: map[int64]*tokenBucket{}}
+}
+
+// Consome um token da chave. Sem tokens, devolve quanto tempo falta para o próximo.
+func (l *RateLimiter) Allow(k *APIKey, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+
+	perSecond := float64(k.RatePerMinute) / 60
+	burst := float64(k.Burst)
+	b, ok := l.buckets[k.ID]
+	if !ok {
+		b = &tokenBucket{tokens: burst, last: now}
+		l.buckets[k.ID] = b
+	}
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(burst, b.tokens+elapsed*perSecond)
+		b.last = now
+	}
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := (1 - b.tokens) / perSecond
+	return false, time.Duration(wait * float64(time.Second))
+}
+
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.swept) < rateLimiterIdle {
+		return
+	}
+	l.swept = now
+	for id, b := range l.buckets {
+		if now.Sub(b.last) > rateLimiterIdle {
+			delete(l.buckets, id)
+		}
+	}
+}
+
+type apiKeyContextKey struct{}
+
+// Chave que autenticou a requisição, ou nil com a autenticação desligada
+func apiKeyFromContext(ctx context.Context) *APIKey {
+	k, _ := ctx.Value(apiKeyContextKey{}).(*APIKey)
+	return k
+}
+
+type Authenticator struct {
+	lookup  func(key string) (*APIKey, error)
+	limiter *RateLimiter
+	now     func() time.Time
+}
+
+func NewAuthenticator(lookup func(key string) (*APIKey, error)) *Authenticator {
+	return &Authenticator{lookup: lookup, limiter: NewRateLimiter(), now: time.Now}
+}
+
+// QUOTE_AUTH=disabled desliga a autenticação (desenvolvimento local); o padrão é exigir chave
+func authRequired() (bool, error) {
+	switch value := os.Getenv("QUOTE_AUTH"); value {
+	case "", "required":
+		return true, nil
+	case "disabled":
+		return false, nil
+	default:
+		return false, errors.New("invalid QUOTE_AUTH: " + value)
+	}
+}
+
+var (
+	errMissingAPIKey = errors.New("missing api key")
+	errRateLimited   = errors.New("rate limit exceeded")
+)
+
+// Valida o valor do header Authorization ("Bearer <chave>") e consome um
+// token da chave. Com errRateLimited, wait diz quando haverá um novo token.
+// Compartilhado pela API HTTP e pelo gRPC.
+func (a *Authenticator) authenticate(authorization string) (k *APIKey, wait time.Duration, err error) {
+	scheme, key, ok := strings.Cut(authorization, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || key == "" {
+		return nil, 0, errMissingAPIKey
+	}
+	k, err = a.lookup(strings.TrimSpace(key))
+	if err != nil {
+		return nil, 0, err
+	}
+	allowed, wait := a.limiter.Allow(k, a.now())
+	if !allowed {
+		log.Printf("Rate limit exceeded for api key %d (%s)\n", k.ID, k.Name)
+		return k, wait, errRateLimited
+	}
+	return k, 0, nil
+}
+
+// O EventSource do navegador não envia headers, então o stream também aceita
+// a chave no parâmetro api_key
+const streamPath = "/quotes/stream"
+
+// Exige Authorization: Bearer <chave> e aplica o limite de requisições da chave
+func (a *Authenticator) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization := r.Header.Get("Authorization")
+		if key := r.URL.Query().Get("api_key"); authorization == "" && key != "" && r.URL.Path == streamPath {
+			authorization = "Bearer " + key
+		}
+		k, wait, err := a.authenticate(authorization)
+		switch {
+		case err == nil:
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiKeyContextKey{}, k)))
+		case errors.Is(err, errMissingAPIKey):
+			w.Header().Set("WWW-Authenticate", `Bearer realm="quotation-api"`)
+			writeProblem(w, r, http.StatusUnauthorized, "missing api key")
+		case errors.Is(err, errAPIKeyNotFound):
+			w.Header().Set("WWW-Authenticate", `Bearer realm="quotation-api", error="invalid_token"`)
+			writeProblem(w, r, http.StatusUnauthorized, "invalid api key")
+		case errors.Is(err, errRateLimited):
+			seconds := int64(math.Ceil(wait.Seconds()))
+			w.Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+			writeProblem(w, r, http.StatusTooManyRequests, "rate limit exceeded")
+		default:
+			writeError(w, r, err)
+		}
+	})
+}
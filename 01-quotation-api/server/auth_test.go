@@ -0,0 +1,106 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterRefills(t *testing.T) {
+	limiter := NewRateLimiter()
+	key := &APIKey{ID: 1, RatePerMinute: 60, Burst: 2}
+	now := time.Unix(1717243200, 0)
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := limiter.Allow(key, now); !ok {
+			t.Fatalf("requisição %d dentro da rajada foi bloqueada", i+1)
+		}
+	}
+	ok, wait := limiter.Allow(key, now)
+	if ok || wait != time.Second {
+		t.Fatalf("terceira requisição deveria esperar 1s: ok=%v wait=%s", ok, wait)
+	}
+	if ok, _ = limiter.Allow(key, now.Add(time.Second)); !ok {
+		t.Errorf("depois de 1s deveria haver um token")
+	}
+	// Outra chave tem o seu próprio bucket
+	if ok, _ = limiter.Allow(&APIKey{ID: 2, RatePerMinute: 60, Burst: 1}, now); !ok {
+		t.Errorf("chave sem uso foi bloqueada")
+	}
+}
+
+func TestAuthenticatorWrap(t *testing.T) {
+	key := &APIKey{ID: 1, Name: "team-a", RatePerMinute: 1, Burst: 1}
+	auth := NewAuthenticator(func(value string) (*APIKey, error) {
+		if value == "qk_valid" {
+			return key, nil
+		}
+		return nil, errAPIKeyNotFound
+	})
+	now := time.Unix(1717243200, 0)
+	auth.now = func() time.Time { return now }
+	handler := auth.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if apiKeyFromContext(r.Context()) != key {
+			t.Errorf("chave não disponível no contexto")
+		}
+	}))
+
+	tests := []struct {
+		name       string
+		header     string
+		status     int
+		retryAfter string
+	}{
+		{"sem chave", "", http.StatusUnauthorized, ""},
+		{"chave inválida", "Bearer qk_other", http.StatusUnauthorized, ""},
+		{"chave válida", "Bearer qk_valid", http.StatusOK, ""},
+		{"limite excedido", "bearer qk_valid", http.StatusTooManyRequests, "60"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/quotes/USD-BRL", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Errorf("status %d, esperado %d", rec.Code, tt.status)
+			}
+			if got := rec.Header().Get("Retry-After"); got != tt.retryAfter {
+				t.Errorf("Retry-After %q, esperado %q", got, tt.retryAfter)
+			}
+		})
+	}
+}
+
+func TestAuthenticatorWrapStreamQueryKey(t *testing.T) {
+	key := &APIKey{ID: 1, Name: "browser", RatePerMinute: 60, Burst: 10}
+	auth := NewAuthenticator(func(value string) (*APIKey, error) {
+		if value == "qk_valid" {
+			return key, nil
+		}
+		return nil, errAPIKeyNotFound
+	})
+	handler := auth.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	tests := []struct {
+		name   string
+		target string
+		status int
+	}{
+		{"stream com api_key", "/quotes/stream?pairs=USD-BRL&api_key=qk_valid", http.StatusOK},
+		{"stream com api_key inválida", "/quotes/stream?api_key=qk_other", http.StatusUnauthorized},
+		{"api_key fora do stream", "/quotes/USD-BRL?api_key=qk_valid", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest("GET", tt.target, nil))
+			if rec.Code != tt.status {
+				t.Errorf("status %d, esperado %d", rec.Code, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+)
+
+const awesomeApiURL = "https://economia.awesomeapi.com.br/json/last/"
+
+type AwesomeApiProvider struct {
+	url    string
+	client *ResilientClient
+}
+
+func NewAwesomeApiProvider(url string, client *ResilientClient) *AwesomeApiProvider {
+	return &AwesomeApiProvider{url: url, client: client}
+}
+
+func (a *AwesomeApiProvider) Breaker() *CircuitBreaker {
+	return a.client.Breaker()
+}
+
+func (a *AwesomeApiProvider) Name() string {
+	return "awesomeapi"
+}
+
+func (a *AwesomeApiProvider) GetQuote(ctx context.Context, p Pair) (*Quote, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", a.url+p.String(), nil)
+	if err != nil {
+		return nil, upstreamError(a.Name(), ErrUpstreamFailure, fmt.Errorf("error creating request: %w", err))
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		// Com o circuito aberto a chamada nem é feita; o cache responde com a última cotação
+		if errors.Is(err, ErrCircuitOpen) {
+			return nil, upstreamError(a.Name(), ErrUpstreamFailure, err)
+		}
+		// Verifica se o erro foi causado pelo timeout
+		if ctx.Err() == context.DeadlineExceeded {
+			log.Println("Request Timeout economia.awesomeapi.com.br")
+			return nil, upstreamError(a.Name(), ErrUpstreamTimeout, fmt.Errorf("request to economia.awesomeapi.com.br: %w", err))
+		}
+		return nil, upstreamError(a.Name(), ErrUpstreamFailure, fmt.Errorf("error executing request: %w", err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errPairNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, upstreamError(a.Name(), ErrUpstreamFailure, errors.New("unexpected status from economia.awesomeapi.com.br: "+resp.Status))
+	}
+
+	res, err := io.ReadAll(resp.Body)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			log.Println("Request Timeout economia.awesomeapi.com.br")
+			return nil, upstreamError(a.Name(), ErrUpstreamTimeout, fmt.Errorf("reading response: %w", err))
+		}
+		return nil, upstreamError(a.Name(), ErrUpstreamFailure, fmt.Errorf("error reading response: %w", err))
+	}
+
+	q, err := decodeQuote(res, p)
+	if err != nil && !errors.Is(err, errPairNotFound) {
+		return nil, upstreamError(a.Name(), ErrUpstreamBadPayload, err)
+	}
+	return q, err
+}
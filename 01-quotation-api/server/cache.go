@@ -0,0 +1,280 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const defaultCacheTTL = 30 * time.Second
+
+type CachedQuote struct {
+	Quote     Quote
+	FetchedAt time.Time
+	Stale     bool
+}
+
+func (c CachedQuote) Age() time.Duration {
+	return time.Since(c.FetchedAt)
+}
+
+// Cache em memória das cotações por par. Dentro do TTL a cotação é servida
+// sem chamar os provedores; fora dele, se todos falharem, é servida a
+// última cotação conhecida (memória ou SQLite) marcada como stale enquanto
+// uma nova é buscada em segundo plano.
+type QuoteCache struct {
+	providers  *ProviderChain
+	store      *Store
+	ttl        time.Duration
+	mu         sync.Mutex
+	entries    map[string]CachedQuote
+	refreshing map[string]bool
+	// Buscas em andamento por par, compartilhadas por quem pede o mesmo par
+	fetching map[string]*fetchCall
+	// Pares atualizados pelo Poller, com o intervalo de atualização
+	polled map[string]time.Duration
+	// Atualizações em segundo plano em andamento, aguardadas no desligamento
+	background sync.WaitGroup
+}
+
+func NewQuoteCache(providers *ProviderChain, store *Store, ttl time.Duration) *QuoteCache {
+	return &QuoteCache{
+		providers:  providers,
+		store:      store,
+		ttl:        ttl,
+		entries:    map[string]CachedQuote{},
+		refreshing: map[string]bool{},
+		fetching:   map[string]*fetchCall{},
+		polled:     map[string]time.Duration{},
+	}
+}
+
+func cacheTTL() (time.Duration, error) {
+	value := os.Getenv("QUOTE_CACHE_TTL")
+	if value == "" {
+		return defaultCacheTTL, nil
+	}
+	ttl, err := time.ParseDuration(value)
+	if err != nil || ttl < 0 {
+		return 0, errors.New("invalid QUOTE_CACHE_TTL: " + value)
+	}
+	return ttl, nil
+}
+
+func (c *QuoteCache) Get(p Pair) (CachedQuote, error) {
+	if interval, ok := c.pollInterval(p); ok {
+		return c.getPolled(p, interval)
+	}
+	if entry, ok := c.lookup(p); ok && entry.Age() < c.ttl {
+		return entry, nil
+	}
+	entry, err := c.fetchShared(p)
+	if err == nil || errors.Is(err, errPairNotFound) {
+		return entry, err
+	}
+	// Falha ao persistir: a cotação buscada é válida, não faz sentido servir
+	// uma cotação antiga no lugar dela
+	if errors.Is(err, ErrDatabase) {
+		return entry, err
+	}
+	stale, ok := c.lastKnown(p)
+	if !ok {
+		return CachedQuote{}, err
+	}
+	log.Printf("Serving stale %s quote (%s old): %v\n", p, stale.Age().Round(time.Second), err)
+	c.refreshInBackground(p)
+	return stale, nil
+}
+
+// Pares consultados pelo Poller são respondidos com a última cotação coletada,
+// sem chamar os provedores. A cotação só é stale se o Poller perdeu mais de
+// uma rodada; os provedores só são chamados se nada foi coletado ainda.
+func (c *QuoteCache) getPolled(p Pair, interval time.Duration) (CachedQuote, error) {
+	entry, ok := c.lookup(p)
+	if !ok {
+		entry, ok = c.lastKnown(p)
+	}
+	if !ok {
+		return c.fetchShared(p)
+	}
+	entry.Stale = entry.Age() > 2*interval
+	return entry, nil
+}
+
+func (c *QuoteCache) Track(p Pair, interval time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.polled[p.String()] = interval
+}
+
+func (c *QuoteCache) pollInterval(p Pair) (time.Duration, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	interval, ok := c.polled[p.String()]
+	return interval, ok
+}
+
+// Busca uma cotação nova nos provedores, registra e atualiza o cache
+func (c *QuoteCache) Refresh(p Pair) (CachedQuote, error) {
+	return c.fetch(p)
+}
+
+func (c *QuoteCache) lookup(p Pair) (CachedQuote, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[p.String()]
+	return entry, ok
+}
+
+func (c *QuoteCache) put(p Pair, entry CachedQuote) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Evita que uma resposta atrasada sobrescreva uma cotação mais nova
+	if current, ok := c.entries[p.String()]; ok && current.FetchedAt.After(entry.FetchedAt) {
+		return
+	}
+	c.entries[p.String()] = entry
+}
+
+// Busca as cotações dos pares em paralelo e registra as que chegaram numa
+// única transação. Devolve as falhas de cada par e a do banco, se houver.
+func (c *QuoteCache) RefreshAll(pairs []Pair) error {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var records []QuoteRecord
+	var errs []error
+	for _, p := range pairs {
+		wg.Add(1)
+		go func(p Pair) {
+			defer wg.Done()
+			entry, err := c.fetchQuote(p)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", p, err))
+				return
+			}
+			records = append(records, QuoteRecord{Pair: p, Quote: &entry.Quote})
+		}(p)
+	}
+	wg.Wait()
+	if err := c.store.SaveQuotes(records); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+func (c *QuoteCache) fetch(p Pair) (CachedQuote, error) {
+	entry, err := c.fetchQuote(p)
+	if err != nil {
+		return CachedQuote{}, err
+	}
+	return entry, c.store.SaveQuote(p, &entry.Quote)
+}
+
+type fetchCall struct {
+	done  chan struct{}
+	entry CachedQuote
+	err   error
+}
+
+// Como fetch, mas pedidos simultâneos do mesmo par esperam a busca já em
+// andamento em vez de chamar os provedores de novo
+func (c *QuoteCache) fetchShared(p Pair) (CachedQuote, error) {
+	c.mu.Lock()
+	if call, ok := c.fetching[p.String()]; ok {
+		c.mu.Unlock()
+		<-call.done
+		return call.entry, call.err
+	}
+	call := &fetchCall{done: make(chan struct{})}
+	c.fetching[p.String()] = call
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.fetching, p.String())
+		c.mu.Unlock()
+		close(call.done)
+	}()
+	call.entry, call.err = c.fetch(p)
+	return call.entry, call.err
+}
+
+// Consulta os provedores e atualiza o cache, sem registrar a cotação
+func (c *QuoteCache) fetchQuote(p Pair) (CachedQuote, error) {
+	q, err := c.providers.GetQuote(context.Background(), p)
+	if err != nil {
+		return CachedQuote{}, err
+	}
+	entry := CachedQuote{Quote: *q, FetchedAt: time.Now()}
+	c.put(p, entry)
+	return entry, nil
+}
+
+func (c *QuoteCache) lastKnown(p Pair) (CachedQuote, bool) {
+	if entry, ok := c.lookup(p); ok {
+		entry.Stale = true
+		return entry, true
+	}
+	stored, err := c.store.LatestQuote(p)
+	if err != nil || !stored.InsertedAt.Valid || !stored.Bid.Valid {
+		return CachedQuote{}, false
+	}
+	return CachedQuote{
+		Quote: Quote{
+			Code:      p.From,
+			Codein:    p.To,
+			Bid:       stored.Bid.Decimal,
+			Ask:       stored.Ask,
+			High:      stored.High,
+			Low:       stored.Low,
+			Timestamp: formatUnixSeconds(stored.SourceTimestamp),
+			Provider:  stored.Provider.String,
+		},
+		FetchedAt: time.Unix(stored.InsertedAt.Int64, 0),
+		Stale:     true,
+	}, true
+}
+
+func (c *QuoteCache) refreshInBackground(p Pair) {
+	c.mu.Lock()
+	if c.refreshing[p.String()] {
+		c.mu.Unlock()
+		return
+	}
+	c.refreshing[p.String()] = true
+	c.background.Add(1)
+	c.mu.Unlock()
+
+	go func() {
+		defer c.background.Done()
+		defer func() {
+			c.mu.Lock()
+			delete(c.refreshing, p.String())
+			c.mu.Unlock()
+		}()
+		_, err := c.fetch(p)
+		if err != nil {
+			log.Printf("Background refresh of %s failed: %v\n", p, err)
+		}
+	}()
+}
+
+func formatUnixSeconds(ts sql.NullInt64) string {
+	if !ts.Valid {
+		return ""
+	}
+	return strconv.FormatInt(ts.Int64, 10)
+}
+
+// Aguarda as atualizações em segundo plano, para que nenhuma escrita se perca no desligamento
+func (c *QuoteCache) Wait() {
+	c.background.Wait()
+}
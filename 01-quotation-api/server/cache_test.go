@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Provedor que conta as chamadas
+type countingProvider struct {
+	stubProvider
+	calls atomic.Int32
+}
+
+func (c *countingProvider) GetQuote(ctx context.Context, p Pair) (*Quote, error) {
+	c.calls.Add(1)
+	return c.stubProvider.GetQuote(ctx, p)
+}
+
+func TestQuoteCacheMergesConcurrentMisses(t *testing.T) {
+	provider := &countingProvider{stubProvider: stubProvider{name: "stub", quote: testQuote(t, "5.1234"), wait: 50 * time.Millisecond}}
+	chain := &ProviderChain{}
+	chain.Add(provider, time.Second)
+	cache := NewQuoteCache(chain, newTestStore(t, defaultStoreTimeouts), time.Minute)
+	usdbrl := Pair{From: "USD", To: "BRL"}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			quote, err := cache.Get(usdbrl)
+			if err != nil || quote.Quote.Bid.String() != "5.1234" {
+				t.Errorf("cotação incorreta: %+v, %v", quote, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if calls := provider.calls.Load(); calls != 1 {
+		t.Errorf("pedidos simultâneos do mesmo par chamaram o provedor %d vezes, esperado 1", calls)
+	}
+
+	// Terminada a busca, um novo pedido fora do TTL busca de novo
+	cache.ttl = 0
+	if _, err := cache.Get(usdbrl); err != nil {
+		t.Fatal(err)
+	}
+	if calls := provider.calls.Load(); calls != 2 {
+		t.Errorf("provedor chamado %d vezes, esperado 2", calls)
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/isaacmirandacampos/go-expert/quotation-api/internal/decimal"
+)
+
+const (
+	defaultConvertScale = 2
+	maxConvertScale     = 18
+	// Casas decimais da taxa quando ela é invertida (1 / bid)
+	invertedRateScale = 10
+)
+
+var errNoStoredQuote = errors.New("no stored quote for pair")
+
+type ConvertResponse struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Amount   string `json:"amount"`
+	Result   string `json:"result"`
+	Rate     string `json:"rate"`
+	Pair     string `json:"pair"`
+	Inverted bool   `json:"inverted"`
+	QuoteID  int64  `json:"quote_id"`
+	QuotedAt string `json:"quoted_at"`
+}
+
+type Conversion struct {
+	Amount   *big.Rat
+	Result   *big.Rat
+	Rate     *big.Rat
+	Quote    *StoredQuote
+	Inverted bool
+}
+
+func (s *Server) convertHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	query := r.URL.Query()
+	pair, err := parsePair(query.Get("from") + "-" + query.Get("to"))
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	amount, err := parseAmount(query.Get("amount"))
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	var at time.Time
+	if value := query.Get("at"); value != "" {
+		at, err = parseTime(value)
+		if err != nil {
+			writeProblem(w, r, http.StatusBadRequest, "invalid at")
+			return
+		}
+	}
+	scale := defaultConvertScale
+	if value := query.Get("scale"); value != "" {
+		scale, err = strconv.Atoi(value)
+		if err != nil || scale < 0 || scale > maxConvertScale {
+			writeProblem(w, r, http.StatusBadRequest, "invalid scale")
+			return
+		}
+	}
+	conversion, err := convert(s.store, pair, amount.Rat(), at)
+	if errors.Is(err, errNoStoredQuote) {
+		writeProblem(w, r, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	rate := conversion.Quote.Bid.Decimal.String()
+	if conversion.Inverted {
+		rate = conversion.Rate.FloatString(invertedRateScale)
+	}
+	json.NewEncoder(w).Encode(ConvertResponse{
+		From:     pair.From,
+		To:       pair.To,
+		Amount:   amount.String(),
+		Result:   conversion.Result.FloatString(scale),
+		Rate:     rate,
+		Pair:     conversion.Quote.Pair,
+		Inverted: conversion.Inverted,
+		QuoteID:  conversion.Quote.ID,
+		QuotedAt: formatUnix(conversion.Quote.InsertedAt),
+	})
+}
+
+// O valor segue a mesma notação dos preços registrados (dígitos e ponto
+// opcional, sem sinal nem expoente) e precisa ser positivo
+func parseAmount(s string) (decimal.Decimal, error) {
+	if s == "" || s[0] == '+' || s[0] == '-' {
+		return decimal.Decimal{}, errors.New("invalid amount")
+	}
+	amount, err := decimal.Parse(s)
+	if err != nil {
+		return decimal.Decimal{}, errors.New("invalid amount")
+	}
+	if amount.Sign() <= 0 {
+		return decimal.Decimal{}, errors.New("amount must be positive")
+	}
+	return amount, nil
+}
+
+// Converte usando o bid da última cotação registrada do par (ou da registrada
+// até `at`). Sem cotação do par, usa o inverso do bid do par oposto.
+func convert(store *Store, p Pair, amount *big.Rat, at time.Time) (*Conversion, error) {
+	inverted := false
+	q, err := store.QuoteAt(p, at)
+	if err == sql.ErrNoRows {
+		inverted = true
+		q, err = store.QuoteAt(Pair{From: p.To, To: p.From}, at)
+	}
+	if err == sql.ErrNoRows {
+		return nil, errNoStoredQuote
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !q.Bid.Valid || q.Bid.Decimal.Sign() <= 0 {
+		return nil, errors.New("stored quote has an invalid bid")
+	}
+	rate := q.Bid.Decimal.Rat()
+	if inverted {
+		rate.Inv(rate)
+	}
+	return &Conversion{
+		Amount:   amount,
+		Result:   new(big.Rat).Mul(amount, rate),
+		Rate:     rate,
+		Quote:    q,
+		Inverted: inverted,
+	}, nil
+}
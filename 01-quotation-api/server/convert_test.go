@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// Servidor com as cotações dadas (par -> bid) registradas no banco
+func newConvertTestServer(t *testing.T, bids map[string]string) *Server {
+	t.Helper()
+	store := newTestStore(t, defaultStoreTimeouts)
+	for pair, bid := range bids {
+		p, err := parsePair(pair)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := store.SaveQuote(p, testQuote(t, bid)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewServer(":0", "", store, NewQuoteCache(&ProviderChain{}, store, time.Minute), nil, NewAlertEvaluator(nil), nil)
+}
+
+func TestConvert(t *testing.T) {
+	server := newConvertTestServer(t, map[string]string{"USD-BRL": "5.5", "BTC-USD": "1.23456789"})
+
+	tests := []struct {
+		name     string
+		query    string
+		status   int
+		result   string
+		rate     string
+		inverted bool
+	}{
+		{"par direto", "from=USD&to=BRL&amount=123.45", http.StatusOK, "678.98", "5.5", false},
+		{"par invertido", "from=BRL&to=USD&amount=11", http.StatusOK, "2.00", "0.1818181818", true},
+		{"arredonda na 8ª casa para cima", "from=BTC&to=USD&amount=0.5&scale=8", http.StatusOK, "0.61728395", "1.23456789", false},
+		{"arredonda na 8ª casa para baixo", "from=BRL&to=USD&amount=1&scale=8", http.StatusOK, "0.18181818", "0.1818181818", true},
+		{"par desconhecido", "from=EUR&to=JPY&amount=10", http.StatusNotFound, "", "", false},
+		{"valor inválido", "from=USD&to=BRL&amount=abc", http.StatusBadRequest, "", "", false},
+		{"escala inválida", "from=USD&to=BRL&amount=10&scale=19", http.StatusBadRequest, "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			server.http.Handler.ServeHTTP(rec, httptest.NewRequest("GET", "/convert?"+tt.query, nil))
+			if rec.Code != tt.status {
+				t.Fatalf("status %d, esperado %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if tt.status != http.StatusOK {
+				if rec.Header().Get("Content-Type") != "application/problem+json" {
+					t.Errorf("erro sem problem+json: %s", rec.Header().Get("Content-Type"))
+				}
+				return
+			}
+			var body ConvertResponse
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Result != tt.result || body.Rate != tt.rate || body.Inverted != tt.inverted {
+				t.Errorf("conversão %+v, esperado result %s, rate %s e inverted %v", body, tt.result, tt.rate, tt.inverted)
+			}
+		})
+	}
+}
+
+func TestConvertRejectsInvalidAmount(t *testing.T) {
+	server := newConvertTestServer(t, map[string]string{"USD-BRL": "5.5"})
+
+	for _, amount := range []string{"", "0x10", "0b11", "0o7", "1_000", "0x1p4", "1e3", "1/2", "+10", "-10", "0", "0.00", ".5", "5.", "1.123456789"} {
+		rec := httptest.NewRecorder()
+		server.http.Handler.ServeHTTP(rec, httptest.NewRequest("GET", "/convert?from=USD&to=BRL&amount="+url.QueryEscape(amount), nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("amount=%q: status %d, esperado 400", amount, rec.Code)
+		}
+		if rec.Header().Get("Content-Type") != "application/problem+json" {
+			t.Errorf("amount=%q: Content-Type %s", amount, rec.Header().Get("Content-Type"))
+		}
+	}
+
+	// O valor devolvido é o normalizado, não o texto recebido
+	rec := httptest.NewRecorder()
+	server.http.Handler.ServeHTTP(rec, httptest.NewRequest("GET", "/convert?from=USD&to=BRL&amount=0010.50", nil))
+	var body ConvertResponse
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusOK || body.Amount != "10.5" || body.Result != "57.75" {
+		t.Errorf("conversão incorreta: %d %+v", rec.Code, body)
+	}
+}
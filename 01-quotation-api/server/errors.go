@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+)
+
+// Categorias de falha, comparáveis com errors.Is, que definem o status HTTP da resposta
+var (
+	ErrUpstreamTimeout    = errors.New("upstream timeout")
+	ErrUpstreamBadPayload = errors.New("upstream bad payload")
+	ErrUpstreamFailure    = errors.New("upstream failure")
+	ErrDatabaseTimeout    = errors.New("database timeout")
+	ErrDatabase           = errors.New("database failure")
+)
+
+// Falha de um provedor de cotações (ou da cadeia inteira, com as falhas de cada um em Err)
+type UpstreamError struct {
+	Provider string
+	Kind     error
+	Err      error
+}
+
+func (e *UpstreamError) Error() string {
+	return e.Provider + ": " + e.Kind.Error() + ": " + e.Err.Error()
+}
+
+func (e *UpstreamError) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+func upstreamError(provider string, kind error, err error) error {
+	return &UpstreamError{Provider: provider, Kind: kind, Err: err}
+}
+
+// Categoria de uma falha de provedor; erros sem tipo contam como falha genérica
+func upstreamKind(err error) error {
+	var upstream *UpstreamError
+	if errors.As(err, &upstream) {
+		return upstream.Kind
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrUpstreamTimeout
+	}
+	return ErrUpstreamFailure
+}
+
+// Falha numa etapa de uma operação no banco: begin, prepare, exec, commit, query ou scan
+type DatabaseError struct {
+	Stage   string
+	Timeout bool
+	Err     error
+}
+
+func (e *DatabaseError) Error() string {
+	if e.Timeout {
+		return "database " + e.Stage + " timeout: " + e.Err.Error()
+	}
+	return "database " + e.Stage + " failed: " + e.Err.Error()
+}
+
+func (e *DatabaseError) Unwrap() []error {
+	if e.Timeout {
+		return []error{ErrDatabaseTimeout, ErrDatabase, e.Err}
+	}
+	return []error{ErrDatabase, e.Err}
+}
+
+// A falha é um timeout se o prazo do contexto da operação estourou
+func databaseError(ctx context.Context, stage string, err error) error {
+	timeout := timedOut(ctx)
+	if timeout {
+		databaseTimeouts.WithLabelValues(stage).Inc()
+	}
+	return &DatabaseError{Stage: stage, Timeout: timeout, Err: err}
+}
+
+// Também reconhece contextos cancelados por um timeout de etapa (ver stageTimeout)
+func timedOut(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), context.DeadlineExceeded)
+}
+
+// Corpo de erro no formato RFC 7807 (application/problem+json)
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Provider string `json:"provider,omitempty"`
+	Stage    string `json:"stage,omitempty"`
+}
+
+func writeJSONProblem(w http.ResponseWriter, p Problem) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.Header().Del("Content-Encoding")
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
+
+// Erros sem categoria própria, como validação de parâmetros
+func writeProblem(w http.ResponseWriter, r *http.Request, status int, detail string) {
+	writeJSONProblem(w, Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: r.URL.Path,
+	})
+}
+
+// Classifica o erro no problem correspondente, com o status HTTP. Também
+// define o código das respostas de erro do gRPC (ver grpcError).
+func classifyError(err error) Problem {
+	var upstream *UpstreamError
+	var database *DatabaseError
+	switch {
+	case errors.Is(err, errInvalidPair):
+		return Problem{Type: "about:blank", Title: http.StatusText(http.StatusBadRequest), Status: http.StatusBadRequest, Detail: err.Error()}
+	case errors.Is(err, errPairNotFound):
+		return Problem{Type: "about:blank", Title: http.StatusText(http.StatusNotFound), Status: http.StatusNotFound, Detail: err.Error()}
+	case errors.As(err, &upstream):
+		switch upstream.Kind {
+		case ErrUpstreamTimeout:
+			return Problem{
+				Type:     "/problems/upstream-timeout",
+				Title:    "Quote provider timeout",
+				Status:   http.StatusGatewayTimeout,
+				Detail:   "no quote provider answered in time",
+				Provider: upstream.Provider,
+			}
+		case ErrUpstreamBadPayload:
+			return Problem{
+				Type:     "/problems/upstream-bad-payload",
+				Title:    "Invalid quote from provider",
+				Status:   http.StatusBadGateway,
+				Detail:   "the quote provider answered with an invalid payload",
+				Provider: upstream.Provider,
+			}
+		default:
+			return Problem{
+				Type:     "/problems/upstream-failure",
+				Title:    "Quote provider failure",
+				Status:   http.StatusBadGateway,
+				Detail:   "the quote provider could not be reached or answered with an error",
+				Provider: upstream.Provider,
+			}
+		}
+	case errors.As(err, &database):
+		if database.Timeout {
+			return Problem{
+				Type:   "/problems/database-timeout",
+				Title:  "Database timeout",
+				Status: http.StatusServiceUnavailable,
+				Detail: "the database did not complete the " + database.Stage + " step in time",
+				Stage:  database.Stage,
+			}
+		}
+		return Problem{
+			Type:   "/problems/database-failure",
+			Title:  "Database failure",
+			Status: http.StatusServiceUnavailable,
+			Detail: "the database failed at the " + database.Stage + " step",
+			Stage:  database.Stage,
+		}
+	default:
+		return Problem{Type: "about:blank", Title: http.StatusText(http.StatusInternalServerError), Status: http.StatusInternalServerError}
+	}
+}
+
+// Converte o erro no status e no problem correspondentes. A causa completa
+// vai para o log; a resposta só descreve a categoria, sem detalhes internos.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	problem := classifyError(err)
+	problem.Instance = r.URL.Path
+	if problem.Status == http.StatusServiceUnavailable {
+		w.Header().Set("Retry-After", "1")
+	}
+	if problem.Status >= http.StatusInternalServerError {
+		log.Printf("%s %s: %d: %v\n", r.Method, r.URL.Path, problem.Status, err)
+	}
+	writeJSONProblem(w, problem)
+}
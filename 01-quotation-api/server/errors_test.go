@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type stubProvider struct {
+	name  string
+	quote *Quote
+	err   error
+	wait  time.Duration
+}
+
+func (s stubProvider) Name() string {
+	return s.name
+}
+
+func (s stubProvider) GetQuote(ctx context.Context, p Pair) (*Quote, error) {
+	if s.wait > 0 {
+		select {
+		case <-time.After(s.wait):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if s.quote != nil {
+		q := *s.quote
+		return &q, nil
+	}
+	return nil, s.err
+}
+
+func TestProviderChainClassifiesFailures(t *testing.T) {
+	usdbrl := Pair{From: "USD", To: "BRL"}
+	slow := stubProvider{name: "slow", wait: time.Second}
+	broken := stubProvider{name: "broken", err: upstreamError("broken", ErrUpstreamBadPayload, errors.New("invalid json"))}
+	unknown := stubProvider{name: "unknown", err: errPairNotFound}
+
+	tests := []struct {
+		name      string
+		providers []QuoteProvider
+		kind      error
+	}{
+		{"todos estouram o prazo", []QuoteProvider{slow, slow}, ErrUpstreamTimeout},
+		{"prazo e payload inválido", []QuoteProvider{slow, broken}, ErrUpstreamBadPayload},
+		{"par desconhecido é ignorado", []QuoteProvider{unknown, slow}, ErrUpstreamTimeout},
+		{"erro sem tipo", []QuoteProvider{stubProvider{name: "down", err: errors.New("connection refused")}}, ErrUpstreamFailure},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := &ProviderChain{}
+			for _, p := range tt.providers {
+				chain.Add(p, 5*time.Millisecond)
+			}
+			_, err := chain.GetQuote(context.Background(), usdbrl)
+			if !errors.Is(err, tt.kind) {
+				t.Fatalf("erro %v deveria ser %v", err, tt.kind)
+			}
+		})
+	}
+
+	chain := &ProviderChain{}
+	chain.Add(unknown, time.Second)
+	if _, err := chain.GetQuote(context.Background(), usdbrl); err != errPairNotFound {
+		t.Errorf("par desconhecido por todos deveria ser errPairNotFound: %v", err)
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	expired, cancel := context.WithDeadline(context.Background(), time.Unix(0, 0))
+	defer cancel()
+
+	tests := []struct {
+		name        string
+		err         error
+		status      int
+		problemType string
+	}{
+		{"par inválido", errInvalidPair, http.StatusBadRequest, "about:blank"},
+		{"par inexistente", errPairNotFound, http.StatusNotFound, "about:blank"},
+		{"timeout do provedor", upstreamError("awesomeapi", ErrUpstreamTimeout, context.DeadlineExceeded), http.StatusGatewayTimeout, "/problems/upstream-timeout"},
+		{"payload inválido", upstreamError("ptax", ErrUpstreamBadPayload, errors.New("invalid json")), http.StatusBadGateway, "/problems/upstream-bad-payload"},
+		{"falha do provedor", upstreamError("ptax", ErrUpstreamFailure, errors.New("500")), http.StatusBadGateway, "/problems/upstream-failure"},
+		{"timeout do banco", databaseError(expired, "commit", context.DeadlineExceeded), http.StatusServiceUnavailable, "/problems/database-timeout"},
+		{"falha do banco", databaseError(context.Background(), "exec", errors.New("disk full")), http.StatusServiceUnavailable, "/problems/database-failure"},
+		{"erro inesperado", errors.New("boom"), http.StatusInternalServerError, "about:blank"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			writeError(rec, httptest.NewRequest("GET", "/quotes/USD-BRL", nil), tt.err)
+			if rec.Code != tt.status {
+				t.Errorf("status %d, esperado %d", rec.Code, tt.status)
+			}
+			if got := rec.Header().Get("Content-Type"); got != "application/problem+json" {
+				t.Errorf("Content-Type %q", got)
+			}
+			var p Problem
+			if err := json.NewDecoder(rec.Body).Decode(&p); err != nil {
+				t.Fatal(err)
+			}
+			if p.Type != tt.problemType || p.Status != tt.status || p.Instance != "/quotes/USD-BRL" {
+				t.Errorf("problem incorreto: %+v", p)
+			}
+		})
+	}
+}
+
+func TestDatabaseErrorUnwrap(t *testing.T) {
+	expired, cancel := context.WithDeadline(context.Background(), time.Unix(0, 0))
+	defer cancel()
+	cause := errors.New("database is locked")
+
+	err := databaseError(expired, "commit", cause)
+	if !errors.Is(err, ErrDatabaseTimeout) || !errors.Is(err, ErrDatabase) || !errors.Is(err, cause) {
+		t.Errorf("timeout deveria ser ErrDatabaseTimeout, ErrDatabase e a causa: %v", err)
+	}
+	err = databaseError(context.Background(), "exec", cause)
+	if errors.Is(err, ErrDatabaseTimeout) || !errors.Is(err, ErrDatabase) {
+		t.Errorf("falha sem timeout classificada errado: %v", err)
+	}
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// A cada exportRowsPerDeadline linhas o prazo de escrita é renovado, para
+// que exportações grandes não esbarrem no WriteTimeout do servidor
+const exportRowsPerDeadline = 1000
+
+type quoteWriter interface {
+	Write(q StoredQuote) error
+	Flush() error
+}
+
+type exportFormat struct {
+	extension   string
+	contentType string
+	writer      func(w io.Writer) quoteWriter
+}
+
+var exportFormats = map[string]exportFormat{
+	"csv":   {extension: "csv", contentType: "text/csv; charset=utf-8", writer: newCSVQuoteWriter},
+	"jsonl": {extension: "jsonl", contentType: "application/x-ndjson", writer: newJSONQuoteWriter},
+}
+
+var csvExportHeader = []string{"id", "pair", "bid", "ask", "high", "low", "timestamp", "provider", "inserted_at"}
+
+type csvQuoteWriter struct {
+	w      *csv.Writer
+	header bool
+}
+
+func newCSVQuoteWriter(w io.Writer) quoteWriter {
+	return &csvQuoteWriter{w: csv.NewWriter(w)}
+}
+
+func (c *csvQuoteWriter) Write(q StoredQuote) error {
+	if !c.header {
+		c.header = true
+		if err := c.w.Write(csvExportHeader); err != nil {
+			return err
+		}
+	}
+	item := newHistoryItem(q)
+	return c.w.Write([]string{
+		strconv.FormatInt(item.ID, 10), item.Pair, item.Bid.String(), item.Ask.String(), item.High.String(), item.Low.String(),
+		item.Timestamp, item.Provider, item.InsertedAt,
+	})
+}
+
+// Mesmo sem linhas o arquivo sai com o cabeçalho
+func (c *csvQuoteWriter) Flush() error {
+	if !c.header {
+		c.header = true
+		c.w.Write(csvExportHeader)
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// Um item do histórico por linha (JSON Lines)
+type jsonQuoteWriter struct {
+	buffer  *bufio.Writer
+	encoder *json.Encoder
+}
+
+func newJSONQuoteWriter(w io.Writer) quoteWriter {
+	buffer := bufio.NewWriter(w)
+	return &jsonQuoteWriter{buffer: buffer, encoder: json.NewEncoder(buffer)}
+}
+
+func (j *jsonQuoteWriter) Write(q StoredQuote) error {
+	return j.encoder.Encode(newHistoryItem(q))
+}
+
+func (j *jsonQuoteWriter) Flush() error {
+	return j.buffer.Flush()
+}
+
+// Filtros da exportação: pair (um ou mais, separados por vírgula), from/to
+// nos formatos do histórico ou month (2024-06) para o mês inteiro em UTC
+func parseExportFilter(values url.Values) (HistoryFilter, error) {
+	filter := HistoryFilter{}
+	var err error
+	if pairs := values.Get("pair"); pairs != "" {
+		filter.Pairs, err = parseStreamPairs(pairs)
+		if err != nil {
+			return filter, err
+		}
+	}
+	if month := values.Get("month"); month != "" {
+		if values.Get("from") != "" || values.Get("to") != "" {
+			return filter, errors.New("month cannot be combined with from/to")
+		}
+		start, err := time.Parse("2006-01", month)
+		if err != nil {
+			return filter, errors.New("invalid month")
+		}
+		filter.From = start
+		filter.To = start.AddDate(0, 1, 0).Add(-time.Second)
+		return filter, nil
+	}
+	if from := values.Get("from"); from != "" {
+		filter.From, err = parseTime(from)
+		if err != nil {
+			return filter, errors.New("invalid from")
+		}
+	}
+	if to := values.Get("to"); to != "" {
+		filter.To, err = parseEndTime(to)
+		if err != nil {
+			return filter, errors.New("invalid to")
+		}
+	}
+	return filter, nil
+}
+
+// Escreve as cotações do filtro em w, comprimindo com gzip se pedido.
+// progress é chamado a cada exportRowsPerDeadline linhas.
+func exportQuotes(ctx context.Context, store *Store, filter HistoryFilter, format exportFormat, w io.Writer, compress bool, progress func()) (int, error) {
+	var zw *gzip.Writer
+	if compress {
+		zw = gzip.NewWriter(w)
+		w = zw
+	}
+	writer := format.writer(w)
+	count := 0
+	err := store.EachQuote(ctx, filter, func(q StoredQuote) error {
+		count++
+		if progress != nil && count%exportRowsPerDeadline == 0 {
+			progress()
+		}
+		return writer.Write(q)
+	})
+	if err != nil {
+		return count, err
+	}
+	if err = writer.Flush(); err != nil {
+		return count, err
+	}
+	if zw != nil {
+		if err = zw.Close(); err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+// Exportações simultâneas permitidas: cada uma segura uma conexão do pool
+// durante toda a resposta, então ficam com no máximo metade dele e ao menos
+// uma conexão sobra para as escritas das cotações. Com uma conexão só, as
+// exportações pela API são recusadas.
+func exportSlots(store *Store) int {
+	conns := store.db.Stats().MaxOpenConnections
+	if conns == 0 {
+		return defaultMaxOpenConns / 2
+	}
+	return conns / 2
+}
+
+// Usa os valores já validados, para que o nome não carregue nada vindo direto da URL
+func exportFilename(filter HistoryFilter, month string, format exportFormat) string {
+	name := "quotes"
+	if len(filter.Pairs) > 0 {
+		name += "-" + strings.Join(filter.Pairs, "_")
+	}
+	if month != "" {
+		name += "-" + month
+	}
+	return name + "." + format.extension
+}
+
+// Com gzip=true a resposta é um arquivo .gz para download; sem ele, clientes
+// que enviam Accept-Encoding: gzip recebem o conteúdo comprimido em trânsito.
+func (s *Server) exportHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	name := query.Get("format")
+	if name == "" {
+		name = "csv"
+	}
+	format, ok := exportFormats[name]
+	if !ok {
+		writeProblem(w, r, http.StatusBadRequest, "format must be csv or jsonl")
+		return
+	}
+	filter, err := parseExportFilter(query)
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	compress := false
+	switch value := query.Get("gzip"); value {
+	case "true", "1":
+		compress = true
+	case "", "false", "0":
+	default:
+		writeProblem(w, r, http.StatusBadRequest, "invalid gzip")
+		return
+	}
+	// Sem vaga livre, recusa em vez de esperar (ver exportSlots)
+	select {
+	case s.exports <- struct{}{}:
+		defer func() { <-s.exports }()
+	default:
+		w.Header().Set("Retry-After", "5")
+		writeProblem(w, r, http.StatusServiceUnavailable, "too many exports in progress")
+		return
+	}
+
+	filename := exportFilename(filter, query.Get("month"), format)
+	if compress {
+		filename += ".gz"
+		w.Header().Set("Content-Type", "application/gzip")
+	} else {
+		w.Header().Set("Content-Type", format.contentType)
+		w.Header().Add("Vary", "Accept-Encoding")
+		if strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			compress = true
+			w.Header().Set("Content-Encoding", "gzip")
+		}
+	}
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Now().Add(writeTimeout))
+	count, err := exportQuotes(r.Context(), s.store, filter, format, w, compress, func() {
+		rc.SetWriteDeadline(time.Now().Add(writeTimeout))
+	})
+	if err != nil {
+		// O status já foi enviado; abortar evita que o cliente receba um arquivo truncado como se estivesse completo
+		log.Printf("Export aborted after %d rows: %v\n", count, err)
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// Subcomando: server export [-format csv|jsonl] [-pair USD-BRL,EUR-BRL] [-month 2024-06 | -from -to] [-gzip] [-output arquivo]
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	name := fs.String("format", "csv", "output format: csv or jsonl")
+	pair := fs.String("pair", "", "comma separated pairs, e.g. USD-BRL,EUR-BRL (default all)")
+	from := fs.String("from", "", "first insertion time (RFC3339, date or unix seconds)")
+	to := fs.String("to", "", "last insertion time (RFC3339, date or unix seconds)")
+	month := fs.String("month", "", "whole month in UTC, e.g. 2024-06")
+	compress := fs.Bool("gzip", false, "compress the output with gzip (implied by a .gz output)")
+	output := fs.String("output", "-", `output file, "-" for stdout`)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	format, ok := exportFormats[*name]
+	if !ok {
+		return errors.New("format must be csv or jsonl")
+	}
+	filter, err := parseExportFilter(url.Values{"pair": {*pair}, "from": {*from}, "to": {*to}, "month": {*month}})
+	if err != nil {
+		return err
+	}
+
+	config, err := storeConfigFromEnv()
+	if err != nil {
+		return err
+	}
+	store, err := NewStore(config)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	var w io.Writer = os.Stdout
+	if *output != "-" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return errors.New("error creating file: " + err.Error())
+		}
+		defer file.Close()
+		w = file
+		*compress = *compress || strings.HasSuffix(*output, ".gz")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	count, err := exportQuotes(ctx, store, filter, format, w, *compress, nil)
+	if err != nil {
+		if *output != "-" {
+			os.Remove(*output)
+		}
+		return err
+	}
+	log.Printf("%d quote(s) exported\n", count)
+	return nil
+}
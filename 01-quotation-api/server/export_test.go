@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseExportFilterMonth(t *testing.T) {
+	filter, err := parseExportFilter(url.Values{"pair": {"usd-brl,EURBRL"}, "month": {"2024-02"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filter.Pairs) != 2 || filter.Pairs[0] != "USD-BRL" || filter.Pairs[1] != "EUR-BRL" {
+		t.Errorf("pares incorretos: %v", filter.Pairs)
+	}
+	if !filter.From.Equal(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)) || !filter.To.Equal(time.Date(2024, 2, 29, 23, 59, 59, 0, time.UTC)) {
+		t.Errorf("período incorreto: %v a %v", filter.From, filter.To)
+	}
+
+	if _, err = parseExportFilter(url.Values{"month": {"2024-02"}, "from": {"2024-01-01"}}); err == nil {
+		t.Errorf("month com from deveria falhar")
+	}
+}
+
+func TestExportWriters(t *testing.T) {
+	quote := StoredQuote{
+		ID:         7,
+		Pair:       "USD-BRL",
+		Bid:        mustDecimal(t, "5.1234"),
+		InsertedAt: sql.NullInt64{Int64: 1717243201, Valid: true},
+	}
+
+	var csvOut bytes.Buffer
+	writer := exportFormats["csv"].writer(&csvOut)
+	if err := writer.Write(quote); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	expected := "id,pair,bid,ask,high,low,timestamp,provider,inserted_at\n7,USD-BRL,5.1234,,,,,,2024-06-01T12:00:01Z\n"
+	if csvOut.String() != expected {
+		t.Errorf("csv incorreto:\n%s", csvOut.String())
+	}
+
+	var jsonOut bytes.Buffer
+	writer = exportFormats["jsonl"].writer(&jsonOut)
+	writer.Write(quote)
+	writer.Flush()
+	expected = `{"id":7,"pair":"USD-BRL","bid":"5.1234","ask":null,"high":null,"low":null,"timestamp":"","provider":"","inserted_at":"2024-06-01T12:00:01Z"}` + "\n"
+	if jsonOut.String() != expected {
+		t.Errorf("jsonl incorreto:\n%s", jsonOut.String())
+	}
+
+	// Sem linhas o csv ainda tem o cabeçalho
+	var empty bytes.Buffer
+	writer = exportFormats["csv"].writer(&empty)
+	writer.Flush()
+	if empty.String() != "id,pair,bid,ask,high,low,timestamp,provider,inserted_at\n" {
+		t.Errorf("csv vazio incorreto: %q", empty.String())
+	}
+}
+
+// Servidor com o banco em arquivo: com ":memory:" cada conexão teria o seu próprio banco
+func newExportTestServer(t *testing.T, conns int) *Server {
+	t.Helper()
+	store, err := NewStore(StoreConfig{Path: filepath.Join(t.TempDir(), "quotes.db"), MaxOpenConns: conns, MaxIdleConns: conns, Timeouts: defaultStoreTimeouts})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+	if _, err = store.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	return NewServer(":0", "", store, NewQuoteCache(&ProviderChain{}, store, 0), nil, NewAlertEvaluator(nil), nil)
+}
+
+func TestExportHandlerLimitsConcurrentExports(t *testing.T) {
+	server := newExportTestServer(t, 4)
+	if cap(server.exports) != 2 {
+		t.Fatalf("%d vagas de exportação para 4 conexões, esperado 2", cap(server.exports))
+	}
+
+	// Todas as vagas ocupadas por exportações em andamento
+	for i := 0; i < cap(server.exports); i++ {
+		server.exports <- struct{}{}
+	}
+	rec := httptest.NewRecorder()
+	server.http.Handler.ServeHTTP(rec, httptest.NewRequest("GET", "/quotes/export", nil))
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") == "" || rec.Header().Get("Content-Type") != "application/problem+json" {
+		t.Fatalf("esperado 503 com Retry-After: %d %v", rec.Code, rec.Header())
+	}
+
+	<-server.exports
+	rec = httptest.NewRecorder()
+	server.http.Handler.ServeHTTP(rec, httptest.NewRequest("GET", "/quotes/export", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("com uma vaga livre a exportação deveria funcionar: %d %s", rec.Code, rec.Body)
+	}
+	if len(server.exports) != cap(server.exports)-1 {
+		t.Errorf("a vaga não foi devolvida ao fim da exportação: %d ocupadas", len(server.exports))
+	}
+
+	// A única conexão fica para as escritas
+	rec = httptest.NewRecorder()
+	newExportTestServer(t, 1).http.Handler.ServeHTTP(rec, httptest.NewRequest("GET", "/quotes/export", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("com uma conexão a exportação deveria ser recusada: %d", rec.Code)
+	}
+}
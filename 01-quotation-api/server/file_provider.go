@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Lê as cotações de um arquivo no mesmo formato da awesomeapi, ex:
+// {"USDBRL": {"bid": "5.1234", ...}}. Serve como último recurso quando
+// nenhum provedor remoto responde.
+type FileProvider struct {
+	path string
+}
+
+func NewFileProvider(path string) *FileProvider {
+	return &FileProvider{path: path}
+}
+
+func (f *FileProvider) Name() string {
+	return "file"
+}
+
+func (f *FileProvider) GetQuote(ctx context.Context, p Pair) (*Quote, error) {
+	content, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil, upstreamError(f.Name(), ErrUpstreamFailure, fmt.Errorf("error reading %s: %w", f.path, err))
+	}
+	q, err := decodeQuote(content, p)
+	if err != nil && !errors.Is(err, errPairNotFound) {
+		return nil, upstreamError(f.Name(), ErrUpstreamBadPayload, err)
+	}
+	return q, err
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/isaacmirandacampos/go-expert/quotation-api/internal/decimal"
+	"github.com/isaacmirandacampos/go-expert/quotation-api/quotepb"
+)
+
+const (
+	defaultGRPCAddr = ":9090"
+	grpcErrorDomain = "quotation-api"
+)
+
+// QUOTE_GRPC_ADDR define o endereço do gRPC; "disabled" desliga o serviço
+func grpcAddrFromEnv() string {
+	switch value := os.Getenv("QUOTE_GRPC_ADDR"); value {
+	case "":
+		return defaultGRPCAddr
+	case "disabled":
+		return ""
+	default:
+		return value
+	}
+}
+
+// QuoteService sobre o mesmo cache, banco e broker da API HTTP
+type quoteService struct {
+	quotepb.UnimplementedQuoteServiceServer
+	server *Server
+}
+
+func newGRPCServer(s *Server, auth *Authenticator) *grpc.Server {
+	var options []grpc.ServerOption
+	if auth != nil {
+		options = append(options, grpc.UnaryInterceptor(auth.unaryInterceptor), grpc.StreamInterceptor(auth.streamInterceptor))
+	}
+	g := grpc.NewServer(options...)
+	quotepb.RegisterQuoteServiceServer(g, &quoteService{server: s})
+	return g
+}
+
+func (q *quoteService) GetQuote(ctx context.Context, req *quotepb.GetQuoteRequest) (*quotepb.Quote, error) {
+	pair, err := parsePair(req.GetPair())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	quote, err := q.server.cache.Get(pair)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	response := newQuoteResponse(pair, quote)
+	return &quotepb.Quote{
+		Pair:       response.Pair,
+		Name:       response.Name,
+		Bid:        response.Bid.String(),
+		Ask:        optionalDecimal(response.Ask),
+		High:       optionalDecimal(response.High),
+		Low:        optionalDecimal(response.Low),
+		Timestamp:  timestampMessage(sourceTimestamp(&quote.Quote)),
+		Provider:   response.Provider,
+		Stale:      response.Stale,
+		AgeSeconds: response.Age,
+	}, nil
+}
+
+func (q *quoteService) ListHistory(ctx context.Context, req *quotepb.ListHistoryRequest) (*quotepb.ListHistoryResponse, error) {
+	filter := HistoryFilter{Cursor: req.GetCursor(), Limit: int(req.GetLimit())}
+	if req.GetPair() != "" {
+		p, err := parsePair(req.GetPair())
+		if err != nil {
+			return nil, grpcError(err)
+		}
+		filter.Pair = p.String()
+	}
+	if req.From != nil {
+		if err := req.From.CheckValid(); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid from")
+		}
+		filter.From = req.From.AsTime()
+	}
+	if req.To != nil {
+		if err := req.To.CheckValid(); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid to")
+		}
+		filter.To = req.To.AsTime()
+	}
+	if filter.Cursor < 0 {
+		return nil, status.Error(codes.InvalidArgument, "invalid cursor")
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultHistoryLimit
+	}
+	if filter.Limit < 0 || filter.Limit > maxHistoryLimit {
+		return nil, status.Error(codes.InvalidArgument, "invalid limit")
+	}
+
+	quotes, err := q.server.store.ListQuotes(filter)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	response := &quotepb.ListHistoryResponse{Quotes: make([]*quotepb.StoredQuote, 0, len(quotes))}
+	for _, stored := range quotes {
+		response.Quotes = append(response.Quotes, storedQuoteMessage(stored))
+	}
+	// Página cheia indica que pode haver mais registros depois do último id
+	if len(quotes) == filter.Limit {
+		response.NextCursor = quotes[len(quotes)-1].ID
+	}
+	return response, nil
+}
+
+func (q *quoteService) WatchQuotes(req *quotepb.WatchQuotesRequest, stream quotepb.QuoteService_WatchQuotesServer) error {
+	pairs := []string{}
+	for _, value := range req.GetPairs() {
+		p, err := parsePair(strings.TrimSpace(value))
+		if err != nil {
+			return grpcError(err)
+		}
+		pairs = append(pairs, p.String())
+	}
+	if req.GetAfterId() < 0 {
+		return status.Error(codes.InvalidArgument, "invalid after_id")
+	}
+
+	// Inscreve antes de ler o histórico para não perder cotações entre os dois
+	sub := q.server.broker.Subscribe(pairs)
+	if sub == nil {
+		return status.Error(codes.Unavailable, "server shutting down")
+	}
+	defer q.server.broker.Unsubscribe(sub)
+
+	send := func(stored StoredQuote) error {
+		return stream.Send(storedQuoteMessage(stored))
+	}
+	err := q.server.relayQuotes(stream.Context(), sub, pairs, req.AfterId != nil, req.GetAfterId(), send, nil)
+	if errors.Is(err, ErrDatabase) {
+		return grpcError(err)
+	}
+	return err
+}
+
+func storedQuoteMessage(q StoredQuote) *quotepb.StoredQuote {
+	return &quotepb.StoredQuote{
+		Id:         q.ID,
+		Pair:       q.Pair,
+		Bid:        optionalDecimal(q.Bid),
+		Ask:        optionalDecimal(q.Ask),
+		High:       optionalDecimal(q.High),
+		Low:        optionalDecimal(q.Low),
+		Timestamp:  timestampMessage(q.SourceTimestamp),
+		Provider:   q.Provider.String,
+		InsertedAt: timestampMessage(q.InsertedAt),
+	}
+}
+
+func optionalDecimal(d decimal.NullDecimal) *string {
+	if !d.Valid {
+		return nil
+	}
+	value := d.Decimal.String()
+	return &value
+}
+
+func timestampMessage(ts sql.NullInt64) *timestamppb.Timestamp {
+	if !ts.Valid {
+		return nil
+	}
+	return timestamppb.New(time.Unix(ts.Int64, 0))
+}
+
+// Mesma classificação das respostas HTTP (ver classifyError). As falhas com
+// categoria própria levam um ErrorInfo cujo Reason é o nome do problem em
+// maiúsculas, ex: /problems/upstream-timeout -> UPSTREAM_TIMEOUT.
+func grpcError(err error) error {
+	problem := classifyError(err)
+	code := codes.Internal
+	switch problem.Status {
+	case http.StatusBadRequest:
+		code = codes.InvalidArgument
+	case http.StatusNotFound:
+		code = codes.NotFound
+	case http.StatusGatewayTimeout:
+		code = codes.DeadlineExceeded
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		code = codes.Unavailable
+	}
+	message := problem.Title
+	if problem.Detail != "" {
+		message = problem.Detail
+	}
+	if code == codes.Internal || code == codes.Unavailable || code == codes.DeadlineExceeded {
+		log.Printf("gRPC %s: %v\n", code, err)
+	}
+	st := status.New(code, message)
+	name, ok := strings.CutPrefix(problem.Type, "/problems/")
+	if !ok {
+		return st.Err()
+	}
+	info := &errdetails.ErrorInfo{
+		Reason:   strings.ToUpper(strings.ReplaceAll(name, "-", "_")),
+		Domain:   grpcErrorDomain,
+		Metadata: map[string]string{},
+	}
+	if problem.Provider != "" {
+		info.Metadata["provider"] = problem.Provider
+	}
+	if problem.Stage != "" {
+		info.Metadata["stage"] = problem.Stage
+	}
+	if detailed, detailsErr := st.WithDetails(info); detailsErr == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// Mesmas regras da API HTTP, com a chave no metadata "authorization"
+func (a *Authenticator) authenticateGRPC(ctx context.Context) (context.Context, error) {
+	authorization := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			authorization = values[0]
+		}
+	}
+	k, wait, err := a.authenticate(authorization)
+	switch {
+	case err == nil:
+		return context.WithValue(ctx, apiKeyContextKey{}, k), nil
+	case errors.Is(err, errMissingAPIKey), errors.Is(err, errAPIKeyNotFound):
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, errRateLimited):
+		st := status.New(codes.ResourceExhausted, err.Error())
+		if detailed, detailsErr := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(wait)}); detailsErr == nil {
+			st = detailed
+		}
+		return nil, st.Err()
+	default:
+		return nil, grpcError(err)
+	}
+}
+
+func (a *Authenticator) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := a.authenticateGRPC(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a *Authenticator) streamInterceptor(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := a.authenticateGRPC(stream.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+}
+
+// Stream com a chave autenticada no contexto
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/isaacmirandacampos/go-expert/quotation-api/quotepb"
+)
+
+// Serve o QuoteService em memória, com um provedor fixo e o banco em memória
+func newTestQuoteClient(t *testing.T, provider QuoteProvider, auth *Authenticator) (quotepb.QuoteServiceClient, *Store) {
+	t.Helper()
+	store := newTestStore(t, defaultStoreTimeouts)
+	chain := &ProviderChain{}
+	chain.Add(provider, 50*time.Millisecond)
+	server := NewServer(":0", "bufconn", store, NewQuoteCache(chain, store, time.Minute), nil, NewAlertEvaluator(nil), auth)
+
+	listener := bufconn.Listen(1 << 20)
+	go server.grpc.Serve(listener)
+	t.Cleanup(func() {
+		server.broker.Close()
+		server.grpc.Stop()
+	})
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return quotepb.NewQuoteServiceClient(conn), store
+}
+
+func TestGRPCGetQuoteAndHistory(t *testing.T) {
+	provider := stubProvider{name: "stub", quote: &Quote{Name: "Dólar", Bid: testQuote(t, "5.1234").Bid, Timestamp: "1717243200"}}
+	client, _ := newTestQuoteClient(t, provider, nil)
+	ctx := context.Background()
+
+	q, err := client.GetQuote(ctx, &quotepb.GetQuoteRequest{Pair: "usd-brl"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.GetPair() != "USD-BRL" || q.GetBid() != "5.1234" || q.Ask != nil || q.GetProvider() != "stub" || q.GetTimestamp().GetSeconds() != 1717243200 {
+		t.Errorf("cotação incorreta: %v", q)
+	}
+
+	history, err := client.ListHistory(ctx, &quotepb.ListHistoryRequest{Pair: "USD-BRL"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history.GetQuotes()) != 1 || history.GetQuotes()[0].GetBid() != "5.1234" || history.GetNextCursor() != 0 {
+		t.Errorf("histórico incorreto: %v", history)
+	}
+
+	_, err = client.GetQuote(ctx, &quotepb.GetQuoteRequest{Pair: "xx"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("par inválido deveria ser InvalidArgument: %v", err)
+	}
+	_, err = client.ListHistory(ctx, &quotepb.ListHistoryRequest{Limit: maxHistoryLimit + 1})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("limite inválido deveria ser InvalidArgument: %v", err)
+	}
+}
+
+func TestGRPCUpstreamTimeoutDetails(t *testing.T) {
+	client, _ := newTestQuoteClient(t, stubProvider{name: "slow", wait: time.Second}, nil)
+
+	_, err := client.GetQuote(context.Background(), &quotepb.GetQuoteRequest{Pair: "USD-BRL"})
+	st := status.Convert(err)
+	if st.Code() != codes.DeadlineExceeded {
+		t.Fatalf("esperado DeadlineExceeded: %v", err)
+	}
+	if len(st.Details()) != 1 {
+		t.Fatalf("esperado um ErrorInfo: %v", st.Details())
+	}
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	if !ok || info.GetReason() != "UPSTREAM_TIMEOUT" || info.GetMetadata()["provider"] != "slow" {
+		t.Errorf("ErrorInfo incorreto: %v", st.Details()[0])
+	}
+}
+
+func TestGRPCWatchQuotesReplaysAndFollows(t *testing.T) {
+	client, store := newTestQuoteClient(t, stubProvider{name: "stub", err: errPairNotFound}, nil)
+	usdbrl := Pair{From: "USD", To: "BRL"}
+	if err := store.SaveQuote(usdbrl, testQuote(t, "5.10")); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := client.WatchQuotes(ctx, &quotepb.WatchQuotesRequest{Pairs: []string{"USD-BRL"}, AfterId: new(int64)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed.GetBid() != "5.1" {
+		t.Errorf("cotação do histórico incorreta: %v", replayed)
+	}
+
+	// Cotações de outros pares não chegam ao stream
+	store.SaveQuote(Pair{From: "EUR", To: "BRL"}, testQuote(t, "6"))
+	if err = store.SaveQuote(usdbrl, testQuote(t, "5.20")); err != nil {
+		t.Fatal(err)
+	}
+	live, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if live.GetBid() != "5.2" || live.GetId() <= replayed.GetId() {
+		t.Errorf("cotação nova incorreta: %v", live)
+	}
+}
+
+func TestGRPCRequiresAPIKey(t *testing.T) {
+	key := &APIKey{ID: 1, Name: "team-a", RatePerMinute: 60, Burst: 1}
+	auth := NewAuthenticator(func(value string) (*APIKey, error) {
+		if value == "qk_valid" {
+			return key, nil
+		}
+		return nil, errAPIKeyNotFound
+	})
+	client, _ := newTestQuoteClient(t, stubProvider{name: "stub", err: errPairNotFound}, auth)
+
+	_, err := client.ListHistory(context.Background(), &quotepb.ListHistoryRequest{})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("sem chave deveria ser Unauthenticated: %v", err)
+	}
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer qk_valid")
+	if _, err = client.ListHistory(ctx, &quotepb.ListHistoryRequest{}); err != nil {
+		t.Errorf("chave válida rejeitada: %v", err)
+	}
+	_, err = client.ListHistory(ctx, &quotepb.ListHistoryRequest{})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("rajada excedida deveria ser ResourceExhausted: %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+)
+
+type healthResponse struct {
+	// ok, degraded (algum circuito aberto) ou unavailable (banco fora)
+	Status    string           `json:"status"`
+	Database  string           `json:"database"`
+	Providers []providerHealth `json:"providers"`
+}
+
+type providerHealth struct {
+	Name                string `json:"name"`
+	Breaker             string `json:"breaker"`
+	ConsecutiveFailures int    `json:"consecutive_failures"`
+	OpenedAt            string `json:"opened_at,omitempty"`
+}
+
+// Com o circuito de um provedor aberto o servidor continua respondendo com as
+// últimas cotações, então só o banco fora do ar responde 503
+func (s *Server) healthHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	response := healthResponse{Status: "ok", Database: "ok", Providers: []providerHealth{}}
+	for _, pb := range s.cache.providers.Breakers() {
+		status := pb.Breaker.Status()
+		health := providerHealth{
+			Name:                pb.Provider,
+			Breaker:             status.State.String(),
+			ConsecutiveFailures: status.ConsecutiveFailures,
+		}
+		if !status.OpenedAt.IsZero() {
+			health.OpenedAt = status.OpenedAt.UTC().Format(time.RFC3339)
+		}
+		if status.State != BreakerClosed {
+			response.Status = "degraded"
+		}
+		response.Providers = append(response.Providers, health)
+	}
+	code := http.StatusOK
+	if err := s.store.Ping(); err != nil {
+		log.Println("Health check failed:", err)
+		response.Status = "unavailable"
+		response.Database = "unavailable"
+		code = http.StatusServiceUnavailable
+	}
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(response)
+}
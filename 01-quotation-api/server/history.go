@@ -0,0 +1,140 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/isaacmirandacampos/go-expert/quotation-api/internal/decimal"
+)
+
+const (
+	defaultHistoryLimit = 100
+	maxHistoryLimit     = 1000
+)
+
+type HistoryItem struct {
+	ID         int64               `json:"id"`
+	Pair       string              `json:"pair"`
+	Bid        decimal.NullDecimal `json:"bid"`
+	Ask        decimal.NullDecimal `json:"ask"`
+	High       decimal.NullDecimal `json:"high"`
+	Low        decimal.NullDecimal `json:"low"`
+	Timestamp  string              `json:"timestamp"`
+	Provider   string              `json:"provider"`
+	InsertedAt string              `json:"inserted_at"`
+}
+
+type HistoryResponse struct {
+	Items      []HistoryItem `json:"items"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+func (s *Server) historyHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	filter, err := parseHistoryFilter(r)
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	quotes, err := s.store.ListQuotes(filter)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	response := HistoryResponse{Items: make([]HistoryItem, 0, len(quotes))}
+	for _, q := range quotes {
+		response.Items = append(response.Items, newHistoryItem(q))
+	}
+	// Página cheia indica que pode haver mais registros depois do último id
+	if len(quotes) == filter.Limit {
+		response.NextCursor = strconv.FormatInt(quotes[len(quotes)-1].ID, 10)
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+func parseHistoryFilter(r *http.Request) (HistoryFilter, error) {
+	query := r.URL.Query()
+	filter := HistoryFilter{Limit: defaultHistoryLimit}
+	var err error
+	if pair := query.Get("pair"); pair != "" {
+		p, err := parsePair(pair)
+		if err != nil {
+			return filter, err
+		}
+		filter.Pair = p.String()
+	}
+	if from := query.Get("from"); from != "" {
+		filter.From, err = parseTime(from)
+		if err != nil {
+			return filter, errors.New("invalid from")
+		}
+	}
+	if to := query.Get("to"); to != "" {
+		filter.To, err = parseEndTime(to)
+		if err != nil {
+			return filter, errors.New("invalid to")
+		}
+	}
+	if cursor := query.Get("cursor"); cursor != "" {
+		filter.Cursor, err = strconv.ParseInt(cursor, 10, 64)
+		if err != nil || filter.Cursor < 0 {
+			return filter, errors.New("invalid cursor")
+		}
+	}
+	if limit := query.Get("limit"); limit != "" {
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil || filter.Limit <= 0 || filter.Limit > maxHistoryLimit {
+			return filter, errors.New("invalid limit")
+		}
+	}
+	return filter, nil
+}
+
+// Aceita RFC3339, data (2006-01-02) ou unix timestamp em segundos
+func parseTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	ts, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(ts, 0), nil
+}
+
+// Como parseTime, mas uma data sem horário vale até o fim do dia, para que
+// to=2024-06-01 inclua as cotações registradas nesse dia
+func parseEndTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t.AddDate(0, 0, 1).Add(-time.Second), nil
+	}
+	return parseTime(s)
+}
+
+func newHistoryItem(q StoredQuote) HistoryItem {
+	return HistoryItem{
+		ID:         q.ID,
+		Pair:       q.Pair,
+		Bid:        q.Bid,
+		Ask:        q.Ask,
+		High:       q.High,
+		Low:        q.Low,
+		Timestamp:  formatUnix(q.SourceTimestamp),
+		Provider:   q.Provider.String,
+		InsertedAt: formatUnix(q.InsertedAt),
+	}
+}
+
+func formatUnix(ts sql.NullInt64) string {
+	if !ts.Valid {
+		return ""
+	}
+	return time.Unix(ts.Int64, 0).UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestParseHistoryFilterTo(t *testing.T) {
+	tests := []struct {
+		name string
+		to   string
+		want time.Time
+	}{
+		{"data inclui o dia inteiro", "2024-06-01", time.Date(2024, 6, 1, 23, 59, 59, 0, time.UTC)},
+		{"RFC3339 é usado como veio", "2024-06-01T12:00:00Z", time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)},
+		{"unix timestamp é usado como veio", "1717243200", time.Unix(1717243200, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := parseHistoryFilter(httptest.NewRequest("GET", "/quotes/history?to="+url.QueryEscape(tt.to), nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !filter.To.Equal(tt.want) {
+				t.Errorf("to = %v, esperado %v", filter.To, tt.want)
+			}
+
+			export, err := parseExportFilter(url.Values{"to": {tt.to}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !export.To.Equal(tt.want) {
+				t.Errorf("to da exportação = %v, esperado %v", export.To, tt.want)
+			}
+		})
+	}
+
+	// from continua no início do dia, então from=to=data cobre o dia todo
+	filter, err := parseHistoryFilter(httptest.NewRequest("GET", "/quotes/history?from=2024-06-01&to=2024-06-01", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	midday := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	if filter.From.After(midday) || filter.To.Before(midday) {
+		t.Errorf("período %v a %v não inclui o meio do dia", filter.From, filter.To)
+	}
+}
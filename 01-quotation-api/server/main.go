@@ -2,167 +2,143 @@ package main
 
 import (
 	"context"
-	"database/sql"
 	"encoding/json"
-	"errors"
-	"io"
 	"log"
 	"net/http"
-	"time"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
 
-	_ "github.com/mattn/go-sqlite3"
-)
+	"github.com/prometheus/client_golang/prometheus"
 
-type DollarConversion struct {
-	USDBRL struct {
-		Price string `json:"bid"`
-	} `json:"USDBRL"`
-}
+	"github.com/isaacmirandacampos/go-expert/quotation-api/internal/decimal"
+)
 
 type DollarConversionResponse struct {
-	Price string `json:"price"`
+	Price decimal.Decimal `json:"price"`
+	Stale bool            `json:"stale,omitempty"`
+	Age   int64           `json:"age,omitempty"`
 }
 
 func main() {
-	log.Default().Println("Starting...")
-	err := migration()
-	if err != nil {
-		panic(err)
-	}
-	http.HandleFunc("/usd-to-brl", handler)
-	log.Default().Println("Running on port 8080...")
-	http.ListenAndServe(":8080", nil)
-}
-
-func handler(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	var dollarConversion DollarConversion
-	err := getDollarConversion(&dollarConversion)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		err := runMigrate(os.Args[2:])
+		if err != nil {
+			log.Fatal(err)
+		}
 		return
 	}
-	err = saveDollarConversion(&dollarConversion)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		err := runExport(os.Args[2:])
+		if err != nil {
+			log.Fatal(err)
+		}
 		return
 	}
-	dollarConversionResponse := DollarConversionResponse{
-		Price: dollarConversion.USDBRL.Price,
+	if len(os.Args) > 1 && os.Args[1] == "keys" {
+		err := runKeys(os.Args[2:])
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
 	}
-	json.NewEncoder(w).Encode(dollarConversionResponse)
-}
-func getDollarConversion(c *DollarConversion) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
-	defer cancel()
-
-	req, err := http.NewRequestWithContext(ctx, "GET", "https://economia.awesomeapi.com.br/json/last/USD-BRL", nil)
+	log.Default().Println("Starting...")
+	config, err := storeConfigFromEnv()
 	if err != nil {
-		return errors.New("error creating request")
+		panic(err)
 	}
-
-	resp, err := http.DefaultClient.Do(req)
+	store, err := NewStore(config)
 	if err != nil {
-		// Verifica se o erro foi causado pelo timeout
-		if ctx.Err() == context.DeadlineExceeded {
-			log.Println("Request Timeout economia.awesomeapi.com.br")
-			return errors.New("Request Timeout economia.awesomeapi.com.br")
-		}
-		return errors.New("error executing request")
+		panic(err)
 	}
-	defer resp.Body.Close()
-
-	res, err := io.ReadAll(resp.Body)
+	_, err = store.Migrate()
 	if err != nil {
-		return errors.New("error reading response")
+		panic(err)
 	}
-
-	err = json.Unmarshal(res, c)
+	ttl, err := cacheTTL()
 	if err != nil {
-		return errors.New("error unmarshalling response")
+		panic(err)
 	}
-
-	return nil
-}
-
-func saveDollarConversion(c *DollarConversion) error {
-	db, err := db_connection()
+	providers, err := providerChainFromEnv()
 	if err != nil {
-		return err
+		panic(err)
 	}
-	defer db.Close()
-
-	// Usando contexto com timeout de 10ms para a transação
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
-	defer cancel()
-
-	// Inicia a transação
-	tx, err := db.BeginTx(ctx, nil)
+	log.Default().Println("Quote providers:", providers)
+	cache := NewQuoteCache(providers, store, ttl)
+	poller, err := pollerFromEnv(cache)
 	if err != nil {
-		// Verifica se o erro foi causado pelo timeout
-		if ctx.Err() == context.DeadlineExceeded {
-			log.Println("Transaction Timeout")
-			return errors.New("Transaction Timeout")
-		}
-		return errors.New("error beginning transaction")
+		panic(err)
 	}
-
-	// Prepara a consulta de inserção
-	stmt, err := db.PrepareContext(ctx, `INSERT INTO usd_to_brl_conversions (price) VALUES (?)`)
+	if poller != nil {
+		log.Default().Printf("Polling %v every %s\n", poller.pairs, poller.interval)
+	}
+	alerts := NewAlertEvaluator(store)
+	store.OnQuoteStored(alerts.Enqueue)
+	ages := newQuoteAgeCollector()
+	last, err := store.LastInsertedAt()
 	if err != nil {
-		// Verifica se o erro foi causado pelo timeout
-		if ctx.Err() == context.DeadlineExceeded {
-			log.Println("Prepare Timeout")
-			return errors.New("Prepare Timeout")
-		}
-		return errors.New("error preparing statement")
+		panic(err)
 	}
-	defer stmt.Close()
-
-	// Executa a inserção
-	_, err = stmt.Exec(c.USDBRL.Price)
+	for pair, at := range last {
+		ages.set(pair, at)
+	}
+	store.OnQuoteStored(ages.Observe)
+	prometheus.MustRegister(ages)
+	required, err := authRequired()
 	if err != nil {
-		// Verifica se o erro foi causado pelo timeout
-		if ctx.Err() == context.DeadlineExceeded {
-			log.Println("Execute Timeout")
-			return errors.New("Execute Timeout")
-		}
-		return errors.New("error inserting into database")
+		panic(err)
 	}
-
-	// Commit da transação
-	if err = tx.Commit(); err != nil {
-		// Verifica se o erro foi causado pelo timeout
-		if ctx.Err() == context.DeadlineExceeded {
-			log.Println("Commit Timeout")
-			return errors.New("Commit Timeout")
-		}
-		return errors.New("error committing transaction")
+	var auth *Authenticator
+	if required {
+		auth = NewAuthenticator(store.LookupAPIKey)
+	} else {
+		log.Default().Println("API key authentication disabled")
 	}
 
-	// Nenhum erro ocorrido, a transação foi bem-sucedida
-	return nil
+	// SIGINT/SIGTERM iniciam o desligamento gracioso
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	server := NewServer(":8080", grpcAddrFromEnv(), store, cache, poller, alerts, auth)
+	if err = server.Run(ctx); err != nil {
+		log.Fatal(err)
+	}
+	log.Default().Println("Stopped")
 }
 
-func db_connection() (*sql.DB, error) {
-	db, err := sql.Open("sqlite3", "./conversions.db")
+func (s *Server) handler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	usdbrl := Pair{From: "USD", To: "BRL"}
+	quote, err := s.cache.Get(usdbrl)
 	if err != nil {
-		return nil, errors.New("error opening database")
+		writeError(w, r, err)
+		return
+	}
+	setAgeHeader(w, quote)
+	dollarConversionResponse := DollarConversionResponse{
+		Price: quote.Quote.Bid,
+		Stale: quote.Stale,
+		Age:   int64(quote.Age().Seconds()),
 	}
-	return db, nil
+	json.NewEncoder(w).Encode(dollarConversionResponse)
 }
 
-func migration() error {
-	db, err := db_connection()
+func (s *Server) quoteHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	pair, err := parsePair(r.PathValue("pair"))
 	if err != nil {
-		return err
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+		return
 	}
-	defer db.Close()
-	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS usd_to_brl_conversions (
-		price REAL
-	)`)
+	quote, err := s.cache.Get(pair)
 	if err != nil {
-		return errors.New("error creating table")
+		writeError(w, r, err)
+		return
 	}
-	return nil
+	setAgeHeader(w, quote)
+	json.NewEncoder(w).Encode(newQuoteResponse(pair, quote))
+}
+
+func setAgeHeader(w http.ResponseWriter, c CachedQuote) {
+	w.Header().Set("Age", strconv.FormatInt(int64(c.Age().Seconds()), 10))
 }
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Métricas expostas em /metrics, no registro padrão do Prometheus
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "quotation_http_requests_total",
+		Help: "HTTP requests by route, method and status code.",
+	}, []string{"route", "method", "code"})
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "quotation_http_request_duration_seconds",
+		Help:    "HTTP request latency by route and method.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "method"})
+	upstreamDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "quotation_upstream_request_duration_seconds",
+		Help: "Quote provider call latency by provider and outcome (ok, not_found, timeout, bad_payload, failure).",
+		// Concentrados em torno dos timeouts padrão dos provedores (200ms)
+		Buckets: []float64{.01, .025, .05, .1, .15, .2, .3, .5, 1, 2},
+	}, []string{"provider", "outcome"})
+	upstreamTimeouts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "quotation_upstream_timeouts_total",
+		Help: "Quote provider calls that exceeded the provider timeout.",
+	}, []string{"provider"})
+	databaseTimeouts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "quotation_db_timeouts_total",
+		Help: "Database operations that exceeded the stage timeout, by stage.",
+	}, []string{"stage"})
+	breakerState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "quotation_circuit_breaker_state",
+		Help: "Circuit breaker state by provider: 0 closed, 1 open, 2 half-open.",
+	}, []string{"provider"})
+)
+
+func init() {
+	// Séries zeradas desde o início, para que alertas com rate() funcionem
+	for _, stage := range []string{"begin", "prepare", "exec", "commit"} {
+		databaseTimeouts.WithLabelValues(stage)
+	}
+}
+
+// Registra a duração e o resultado de uma chamada a um provedor
+func observeUpstream(provider string, start time.Time, err error) {
+	outcome := "ok"
+	switch {
+	case err == nil:
+	case errors.Is(err, errPairNotFound):
+		outcome = "not_found"
+	case upstreamKind(err) == ErrUpstreamTimeout:
+		outcome = "timeout"
+		upstreamTimeouts.WithLabelValues(provider).Inc()
+	case upstreamKind(err) == ErrUpstreamBadPayload:
+		outcome = "bad_payload"
+	default:
+		outcome = "failure"
+	}
+	upstreamDuration.WithLabelValues(provider, outcome).Observe(time.Since(start).Seconds())
+}
+
+// Idade da última cotação registrada de cada par, calculada a cada coleta
+type quoteAgeCollector struct {
+	mu   sync.Mutex
+	last map[string]time.Time
+	now  func() time.Time
+	desc *prometheus.Desc
+}
+
+func newQuoteAgeCollector() *quoteAgeCollector {
+	return &quoteAgeCollector{
+		last: map[string]time.Time{},
+		now:  time.Now,
+		desc: prometheus.NewDesc("quotation_last_quote_age_seconds", "Seconds since the last quote of the pair was stored.", []string{"pair"}, nil),
+	}
+}
+
+// Listener de cotações registradas (ver Store.OnQuoteStored)
+func (c *quoteAgeCollector) Observe(q StoredQuote) {
+	if !q.InsertedAt.Valid {
+		return
+	}
+	c.set(q.Pair, time.Unix(q.InsertedAt.Int64, 0))
+}
+
+func (c *quoteAgeCollector) set(pair string, at time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if at.After(c.last[pair]) {
+		c.last[pair] = at
+	}
+}
+
+func (c *quoteAgeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *quoteAgeCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for pair, at := range c.last {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, now.Sub(at).Seconds(), pair)
+	}
+}
+
+// Parte da última cotação de cada par já registrada, para que a idade seja
+// conhecida logo depois de reiniciar o servidor
+func (s *Store) LastInsertedAt() (map[string]time.Time, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Query)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `SELECT pair, MAX(inserted_at) FROM quotes WHERE inserted_at IS NOT NULL GROUP BY pair`)
+	if err != nil {
+		return nil, databaseError(ctx, "query", err)
+	}
+	defer rows.Close()
+
+	last := map[string]time.Time{}
+	for rows.Next() {
+		var pair string
+		var insertedAt int64
+		if err = rows.Scan(&pair, &insertedAt); err != nil {
+			return nil, databaseError(ctx, "scan", err)
+		}
+		last[pair] = time.Unix(insertedAt, 0)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, databaseError(ctx, "query", err)
+	}
+	return last, nil
+}
+
+// Guarda o status escrito pelo handler. Unwrap mantém o ResponseController
+// (flush e deadlines de escrita do stream e da exportação) funcionando.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// Métodos fora dessa lista viram "OTHER", para não criar uma série por valor arbitrário
+var knownMethods = map[string]bool{
+	http.MethodGet: true, http.MethodHead: true, http.MethodPost: true, http.MethodPut: true,
+	http.MethodPatch: true, http.MethodDelete: true, http.MethodOptions: true,
+}
+
+// Conta e mede as requisições pela rota do mux (ex: "/quotes/{pair}"), não
+// pelo caminho, para manter um número fixo de séries
+func instrument(route func(*http.Request) string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method := r.Method
+		if !knownMethods[method] {
+			method = "OTHER"
+		}
+		path := route(r)
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+		// Registrado mesmo quando o handler aborta a resposta com panic
+		defer func() {
+			httpDuration.WithLabelValues(path, method).Observe(time.Since(start).Seconds())
+			httpRequests.WithLabelValues(path, method, strconv.Itoa(rec.status)).Inc()
+		}()
+		next.ServeHTTP(rec, r)
+	})
+}
+
+// Rota de um padrão do ServeMux sem o método ("GET /quotes/{pair}" -> "/quotes/{pair}")
+func muxRoute(mux *http.ServeMux, r *http.Request) string {
+	_, pattern := mux.Handler(r)
+	if pattern == "" {
+		return "unmatched"
+	}
+	if _, path, ok := strings.Cut(pattern, " "); ok {
+		return path
+	}
+	return pattern
+}
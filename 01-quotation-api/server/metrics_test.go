@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestInstrumentLabelsByRoute(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /quotes/{pair}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("pair") == "XXX" {
+			writeProblem(w, r, http.StatusBadRequest, "invalid currency pair")
+		}
+	})
+	handler := instrument(func(r *http.Request) string { return muxRoute(mux, r) }, mux)
+
+	ok := httpRequests.WithLabelValues("/quotes/{pair}", "GET", "200")
+	bad := httpRequests.WithLabelValues("/quotes/{pair}", "GET", "400")
+	unmatched := httpRequests.WithLabelValues("unmatched", "OTHER", "404")
+	okBefore, badBefore, unmatchedBefore := testutil.ToFloat64(ok), testutil.ToFloat64(bad), testutil.ToFloat64(unmatched)
+
+	for _, path := range []string{"/quotes/USD-BRL", "/quotes/EUR-BRL", "/quotes/XXX"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("BREW", "/coffee", nil))
+
+	if got := testutil.ToFloat64(ok) - okBefore; got != 2 {
+		t.Errorf("%v requisições 200 contadas, esperado 2", got)
+	}
+	if got := testutil.ToFloat64(bad) - badBefore; got != 1 {
+		t.Errorf("%v requisições 400 contadas, esperado 1", got)
+	}
+	if got := testutil.ToFloat64(unmatched) - unmatchedBefore; got != 1 {
+		t.Errorf("%v requisições sem rota contadas, esperado 1", got)
+	}
+}
+
+func TestObserveUpstreamCountsTimeouts(t *testing.T) {
+	timeouts := upstreamTimeouts.WithLabelValues("metrics-test")
+	before := testutil.ToFloat64(timeouts)
+	observeUpstream("metrics-test", time.Now(), upstreamError("metrics-test", ErrUpstreamTimeout, context.DeadlineExceeded))
+	observeUpstream("metrics-test", time.Now(), upstreamError("metrics-test", ErrUpstreamBadPayload, errors.New("invalid json")))
+	observeUpstream("metrics-test", time.Now(), errPairNotFound)
+	if got := testutil.ToFloat64(timeouts) - before; got != 1 {
+		t.Errorf("%v timeouts contados, esperado 1", got)
+	}
+	if got := testutil.CollectAndCount(upstreamDuration, "quotation_upstream_request_duration_seconds"); got < 3 {
+		t.Errorf("esperadas séries de latência para timeout, bad_payload e not_found: %d", got)
+	}
+}
+
+func TestDatabaseErrorCountsTimeouts(t *testing.T) {
+	expired, cancel := context.WithDeadline(context.Background(), time.Unix(0, 0))
+	defer cancel()
+	commit := databaseTimeouts.WithLabelValues("commit")
+	before := testutil.ToFloat64(commit)
+
+	databaseError(expired, "commit", context.DeadlineExceeded)
+	databaseError(context.Background(), "commit", errors.New("disk full"))
+	if got := testutil.ToFloat64(commit) - before; got != 1 {
+		t.Errorf("%v timeouts de commit contados, esperado 1", got)
+	}
+}
+
+func TestQuoteAgeCollector(t *testing.T) {
+	ages := newQuoteAgeCollector()
+	now := time.Unix(1717243200, 0)
+	ages.now = func() time.Time { return now }
+	ages.Observe(StoredQuote{Pair: "USD-BRL", InsertedAt: sql.NullInt64{Int64: now.Unix() - 90, Valid: true}})
+	ages.Observe(StoredQuote{Pair: "EUR-BRL", InsertedAt: sql.NullInt64{Int64: now.Unix() - 5, Valid: true}})
+	// Uma cotação mais antiga não volta a idade
+	ages.set("EUR-BRL", now.Add(-time.Hour))
+
+	expected := `
+# HELP quotation_last_quote_age_seconds Seconds since the last quote of the pair was stored.
+# TYPE quotation_last_quote_age_seconds gauge
+quotation_last_quote_age_seconds{pair="EUR-BRL"} 5
+quotation_last_quote_age_seconds{pair="USD-BRL"} 90
+`
+	if err := testutil.CollectAndCompare(ages, strings.NewReader(expected)); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Migration
+	AppliedAt time.Time
+}
+
+// Lê os arquivos migrations/NNNN_nome.up.sql e NNNN_nome.down.sql em ordem de versão
+func loadMigrations() ([]Migration, error) {
+	files, err := fs.Glob(migrationsFS, "migrations/*.sql")
+	if err != nil {
+		return nil, errors.New("error listing migrations")
+	}
+	byVersion := map[int]*Migration{}
+	for _, file := range files {
+		base := strings.TrimPrefix(file, "migrations/")
+		name, direction, ok := strings.Cut(strings.TrimSuffix(base, ".sql"), ".")
+		if !ok || (direction != "up" && direction != "down") {
+			return nil, errors.New("invalid migration file name: " + base)
+		}
+		prefix, description, ok := strings.Cut(name, "_")
+		if !ok {
+			return nil, errors.New("invalid migration file name: " + base)
+		}
+		version, err := strconv.Atoi(prefix)
+		if err != nil || version <= 0 {
+			return nil, errors.New("invalid migration version: " + base)
+		}
+		content, err := migrationsFS.ReadFile(file)
+		if err != nil {
+			return nil, errors.New("error reading migration " + base)
+		}
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: description}
+			byVersion[version] = m
+		}
+		if m.Name != description {
+			return nil, errors.New("duplicated migration version: " + base)
+		}
+		if direction == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %04d_%s has no up file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+func ensureMigrationsTable(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at INTEGER NOT NULL
+	)`)
+	if err != nil {
+		return errors.New("error creating schema_migrations table")
+	}
+	return nil
+}
+
+func appliedMigrations(db *sql.DB) (map[int]time.Time, error) {
+	err := ensureMigrationsTable(db)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.Query(`SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, errors.New("error reading schema_migrations")
+	}
+	defer rows.Close()
+	applied := map[int]time.Time{}
+	for rows.Next() {
+		var version int
+		var appliedAt int64
+		if err = rows.Scan(&version, &appliedAt); err != nil {
+			return nil, errors.New("error reading schema_migrations")
+		}
+		applied[version] = time.Unix(appliedAt, 0)
+	}
+	return applied, rows.Err()
+}
+
+// Aplica todas as migrations pendentes, cada uma na sua própria transação
+func migrateUp(db *sql.DB) (int, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return 0, err
+	}
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, m := range migrations {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		err = runMigration(db, m.Up, func(tx *sql.Tx) error {
+			_, err := tx.Exec(`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`, m.Version, m.Name, time.Now().Unix())
+			return err
+		})
+		if err != nil {
+			return count, fmt.Errorf("error applying migration %04d_%s: %v", m.Version, m.Name, err)
+		}
+		log.Printf("Applied migration %04d_%s\n", m.Version, m.Name)
+		count++
+	}
+	return count, nil
+}
+
+// Reverte as últimas `steps` migrations aplicadas
+func migrateDown(db *sql.DB, steps int) (int, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return 0, err
+	}
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for i := len(migrations) - 1; i >= 0 && count < steps; i-- {
+		m := migrations[i]
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+		if m.Down == "" {
+			return count, fmt.Errorf("migration %04d_%s has no down file", m.Version, m.Name)
+		}
+		err = runMigration(db, m.Down, func(tx *sql.Tx) error {
+			_, err := tx.Exec(`DELETE FROM schema_migrations WHERE version = ?`, m.Version)
+			return err
+		})
+		if err != nil {
+			return count, fmt.Errorf("error reverting migration %04d_%s: %v", m.Version, m.Name, err)
+		}
+		log.Printf("Reverted migration %04d_%s\n", m.Version, m.Name)
+		count++
+	}
+	return count, nil
+}
+
+func runMigration(db *sql.DB, script string, record func(tx *sql.Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err = tx.Exec(script); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err = record(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func migrationStatus(db *sql.DB) ([]MigrationStatus, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+	status := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		status = append(status, MigrationStatus{Migration: m, AppliedAt: applied[m.Version]})
+	}
+	return status, nil
+}
+
+// Subcomando: server migrate [up | down [n] | status]
+func runMigrate(args []string) error {
+	config, err := storeConfigFromEnv()
+	if err != nil {
+		return err
+	}
+	store, err := NewStore(config)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	db := store.db
+
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+	switch command {
+	case "up":
+		count, err := migrateUp(db)
+		if err != nil {
+			return err
+		}
+		log.Printf("%d migration(s) applied\n", count)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps <= 0 {
+				return errors.New("invalid number of steps: " + args[1])
+			}
+		}
+		count, err := migrateDown(db, steps)
+		if err != nil {
+			return err
+		}
+		log.Printf("%d migration(s) reverted\n", count)
+	case "status":
+		status, err := migrationStatus(db)
+		if err != nil {
+			return err
+		}
+		for _, s := range status {
+			appliedAt := "pending"
+			if !s.AppliedAt.IsZero() {
+				appliedAt = "applied at " + s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%04d_%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+	default:
+		return errors.New("usage: server migrate [up | down [n] | status]")
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"database/sql"
+	"testing"
+)
+
+// Banco em memória com uma única conexão, já com a tabela da primeira versão do servidor
+func newLegacyDB(t *testing.T, prices ...float64) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	if _, err = db.Exec(`CREATE TABLE usd_to_brl_conversions (price REAL)`); err != nil {
+		t.Fatal(err)
+	}
+	for _, price := range prices {
+		if _, err = db.Exec(`INSERT INTO usd_to_brl_conversions (price) VALUES (?)`, price); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+func queryInt(t *testing.T, db *sql.DB, query string) int {
+	t.Helper()
+	var n int
+	if err := db.QueryRow(query).Scan(&n); err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	return n
+}
+
+func tableExists(t *testing.T, db *sql.DB, name string) bool {
+	t.Helper()
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, name).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n == 1
+}
+
+func appliedVersions(t *testing.T, db *sql.DB) []int {
+	t.Helper()
+	status, err := migrationStatus(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var versions []int
+	for _, s := range status {
+		if !s.AppliedAt.IsZero() {
+			versions = append(versions, s.Version)
+		}
+	}
+	return versions
+}
+
+func TestLoadMigrationsInVersionOrder(t *testing.T) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("nenhuma migration embutida")
+	}
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Errorf("migration %d tem a versão %d: as versões devem ser sequenciais", i, m.Version)
+		}
+		if m.Up == "" || m.Down == "" {
+			t.Errorf("migration %04d_%s sem up ou down", m.Version, m.Name)
+		}
+	}
+}
+
+func TestMigrateUpDownAndReapply(t *testing.T) {
+	db := newLegacyDB(t, 5.1234, 5.4321)
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	count, err := migrateUp(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != len(migrations) {
+		t.Fatalf("%d migrations aplicadas, esperado %d", count, len(migrations))
+	}
+	if tableExists(t, db, "usd_to_brl_conversions") {
+		t.Error("a tabela antiga deveria ser removida depois da importação")
+	}
+	if n := queryInt(t, db, `SELECT COUNT(*) FROM quotes WHERE pair = 'USD-BRL' AND inserted_at IS NULL`); n != 2 {
+		t.Fatalf("%d cotações importadas, esperado 2", n)
+	}
+	if bid := queryInt(t, db, `SELECT MIN(bid) FROM quotes`); bid != 512340000 {
+		t.Errorf("bid importado = %d, esperado 512340000 unidades", bid)
+	}
+
+	// Rodar de novo não aplica nada nem importa as cotações outra vez
+	if count, err = migrateUp(db); err != nil || count != 0 {
+		t.Fatalf("segunda execução aplicou %d migrations: %v", count, err)
+	}
+	if n := queryInt(t, db, `SELECT COUNT(*) FROM quotes`); n != 2 {
+		t.Errorf("%d cotações depois da segunda execução, esperado 2", n)
+	}
+
+	// Reverte todas, da última para a primeira
+	if count, err = migrateDown(db, len(migrations)); err != nil || count != len(migrations) {
+		t.Fatalf("%d migrations revertidas: %v", count, err)
+	}
+	if versions := appliedVersions(t, db); len(versions) != 0 {
+		t.Errorf("migrations ainda aplicadas: %v", versions)
+	}
+	if tableExists(t, db, "quotes") {
+		t.Error("a tabela quotes deveria ser removida")
+	}
+	if n := queryInt(t, db, `SELECT COUNT(*) FROM usd_to_brl_conversions`); n != 2 {
+		t.Errorf("%d preços devolvidos à tabela antiga, esperado 2", n)
+	}
+
+	// Reaplicadas, as cotações antigas são importadas uma única vez
+	if count, err = migrateUp(db); err != nil || count != len(migrations) {
+		t.Fatalf("%d migrations reaplicadas: %v", count, err)
+	}
+	if n := queryInt(t, db, `SELECT COUNT(*) FROM quotes`); n != 2 {
+		t.Errorf("%d cotações depois de reaplicar, esperado 2", n)
+	}
+}
+
+func TestMigrateDownRevertsLatestFirst(t *testing.T) {
+	db := newLegacyDB(t)
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = migrateUp(db); err != nil {
+		t.Fatal(err)
+	}
+
+	count, err := migrateDown(db, 2)
+	if err != nil || count != 2 {
+		t.Fatalf("%d migrations revertidas: %v", count, err)
+	}
+	versions := appliedVersions(t, db)
+	if len(versions) != len(migrations)-2 || versions[len(versions)-1] != migrations[len(migrations)-3].Version {
+		t.Errorf("deveriam ser revertidas as duas últimas, aplicadas: %v", versions)
+	}
+
+	// Só as pendentes são aplicadas
+	if count, err = migrateUp(db); err != nil || count != 2 {
+		t.Errorf("%d migrations aplicadas, esperado 2: %v", count, err)
+	}
+}
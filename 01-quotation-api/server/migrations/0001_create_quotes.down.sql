@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_quotes_pair_inserted_at;
+
+DROP TABLE IF EXISTS quotes;
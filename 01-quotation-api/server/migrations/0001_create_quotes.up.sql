@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS quotes (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	pair TEXT NOT NULL,
+	bid REAL,
+	ask REAL,
+	high REAL,
+	low REAL,
+	source_timestamp INTEGER,
+	inserted_at INTEGER
+);
+
+CREATE INDEX IF NOT EXISTS idx_quotes_pair_inserted_at ON quotes (pair, inserted_at);
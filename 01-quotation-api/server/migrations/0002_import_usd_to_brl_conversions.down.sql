@@ -0,0 +1,7 @@
+CREATE TABLE usd_to_brl_conversions (
+	price REAL
+);
+
+INSERT INTO usd_to_brl_conversions (price) SELECT bid FROM quotes WHERE pair = 'USD-BRL' AND inserted_at IS NULL;
+
+DELETE FROM quotes WHERE pair = 'USD-BRL' AND inserted_at IS NULL;
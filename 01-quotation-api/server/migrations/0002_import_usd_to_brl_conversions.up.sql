@@ -0,0 +1,8 @@
+-- Tabela da primeira versão do servidor, que guardava apenas o preço do dólar
+CREATE TABLE IF NOT EXISTS usd_to_brl_conversions (
+	price REAL
+);
+
+INSERT INTO quotes (pair, bid) SELECT 'USD-BRL', price FROM usd_to_brl_conversions;
+
+DROP TABLE usd_to_brl_conversions;
@@ -0,0 +1,22 @@
+CREATE TABLE quotes_old (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	pair TEXT NOT NULL,
+	bid REAL,
+	ask REAL,
+	high REAL,
+	low REAL,
+	source_timestamp INTEGER,
+	inserted_at INTEGER,
+	provider TEXT
+);
+
+INSERT INTO quotes_old (id, pair, bid, ask, high, low, source_timestamp, inserted_at, provider)
+SELECT id, pair, bid / 100000000.0, ask / 100000000.0, high / 100000000.0, low / 100000000.0,
+	source_timestamp, inserted_at, provider
+FROM quotes;
+
+DROP TABLE quotes;
+
+ALTER TABLE quotes_old RENAME TO quotes;
+
+CREATE INDEX idx_quotes_pair_inserted_at ON quotes (pair, inserted_at);
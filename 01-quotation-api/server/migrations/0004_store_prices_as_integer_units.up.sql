@@ -0,0 +1,28 @@
+-- Preços passam a ser guardados como inteiros em unidades de 10^-8
+-- (ver internal/decimal), sem depender da conversão de REAL do SQLite
+CREATE TABLE quotes_new (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	pair TEXT NOT NULL,
+	bid INTEGER,
+	ask INTEGER,
+	high INTEGER,
+	low INTEGER,
+	source_timestamp INTEGER,
+	inserted_at INTEGER,
+	provider TEXT
+);
+
+INSERT INTO quotes_new (id, pair, bid, ask, high, low, source_timestamp, inserted_at, provider)
+SELECT rowid, pair,
+	CAST(ROUND(bid * 100000000) AS INTEGER),
+	CAST(ROUND(ask * 100000000) AS INTEGER),
+	CAST(ROUND(high * 100000000) AS INTEGER),
+	CAST(ROUND(low * 100000000) AS INTEGER),
+	source_timestamp, inserted_at, provider
+FROM quotes;
+
+DROP TABLE quotes;
+
+ALTER TABLE quotes_new RENAME TO quotes;
+
+CREATE INDEX idx_quotes_pair_inserted_at ON quotes (pair, inserted_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_alerts_pair;
+
+DROP TABLE IF EXISTS alerts;
@@ -0,0 +1,18 @@
+CREATE TABLE alerts (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	pair TEXT NOT NULL,
+	-- above / below: bid acima / abaixo de threshold
+	-- change: bid variou mais que percent% em relação a window_seconds atrás
+	kind TEXT NOT NULL,
+	threshold INTEGER,
+	percent INTEGER,
+	window_seconds INTEGER,
+	url TEXT NOT NULL,
+	secret TEXT NOT NULL,
+	-- O alerta dispara quando a condição passa a valer e só é rearmado quando ela deixa de valer
+	armed INTEGER NOT NULL DEFAULT 1,
+	created_at INTEGER NOT NULL,
+	last_triggered_at INTEGER
+);
+
+CREATE INDEX idx_alerts_pair ON alerts (pair);
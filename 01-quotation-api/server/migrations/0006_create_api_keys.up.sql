@@ -0,0 +1,13 @@
+CREATE TABLE api_keys (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	-- Apenas o SHA-256 da chave é guardado; a chave só é mostrada na criação
+	key_hash TEXT NOT NULL UNIQUE,
+	-- Início da chave, para identificá-la em listagens e logs
+	prefix TEXT NOT NULL,
+	-- Token bucket: requisições por minuto e rajada máxima
+	rate_per_minute INTEGER NOT NULL,
+	burst INTEGER NOT NULL,
+	created_at INTEGER NOT NULL,
+	revoked_at INTEGER
+);
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+const defaultPollInterval = 30 * time.Second
+
+// Coleta as cotações dos pares configurados a cada intervalo, gerando um
+// histórico espaçado independente do tráfego do servidor.
+type Poller struct {
+	cache    *QuoteCache
+	pairs    []Pair
+	interval time.Duration
+}
+
+func NewPoller(cache *QuoteCache, pairs []Pair, interval time.Duration) *Poller {
+	for _, p := range pairs {
+		cache.Track(p, interval)
+	}
+	return &Poller{cache: cache, pairs: pairs, interval: interval}
+}
+
+// Lê QUOTE_POLL_PAIRS (ex: "USD-BRL,EUR-BRL") e QUOTE_POLL_INTERVAL.
+// Sem pares configurados não há Poller.
+func pollerFromEnv(cache *QuoteCache) (*Poller, error) {
+	value := os.Getenv("QUOTE_POLL_PAIRS")
+	if value == "" {
+		return nil, nil
+	}
+	var pairs []Pair
+	for _, entry := range strings.Split(value, ",") {
+		p, err := parsePair(entry)
+		if err != nil {
+			return nil, errors.New("invalid pair in QUOTE_POLL_PAIRS: " + entry)
+		}
+		pairs = append(pairs, p)
+	}
+	interval := defaultPollInterval
+	if value := os.Getenv("QUOTE_POLL_INTERVAL"); value != "" {
+		var err error
+		interval, err = time.ParseDuration(value)
+		if err != nil || interval <= 0 {
+			return nil, errors.New("invalid QUOTE_POLL_INTERVAL: " + value)
+		}
+	}
+	return NewPoller(cache, pairs, interval), nil
+}
+
+func (p *Poller) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		p.poll()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// As cotações de uma rodada são registradas juntas, numa única transação
+func (p *Poller) poll() {
+	if err := p.cache.RefreshAll(p.pairs); err != nil {
+		log.Printf("Polling failed: %v\n", err)
+	}
+}
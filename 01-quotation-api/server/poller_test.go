@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPollerRecordsEachRound(t *testing.T) {
+	store := newTestStore(t, defaultStoreTimeouts)
+	provider := &countingProvider{stubProvider: stubProvider{name: "stub", quote: testQuote(t, "5.1234")}}
+	chain := &ProviderChain{}
+	chain.Add(provider, time.Second)
+	cache := NewQuoteCache(chain, store, 0)
+	usdbrl, eurbrl := Pair{From: "USD", To: "BRL"}, Pair{From: "EUR", To: "BRL"}
+	poller := NewPoller(cache, []Pair{usdbrl, eurbrl}, time.Minute)
+
+	poller.poll()
+	if n := countQuotes(t, store); n != 2 {
+		t.Fatalf("%d cotações registradas na rodada, esperado 2", n)
+	}
+
+	// Pares coletados são servidos sem chamar os provedores, mesmo com TTL zero
+	calls := provider.calls.Load()
+	quote, err := cache.Get(usdbrl)
+	if err != nil || quote.Stale || quote.Quote.Provider != "stub" {
+		t.Fatalf("cotação coletada incorreta: %+v, %v", quote, err)
+	}
+	if provider.calls.Load() != calls {
+		t.Errorf("par coletado não deveria chamar o provedor")
+	}
+}
+
+func TestPollerKeepsGoingWhenAPairFails(t *testing.T) {
+	store := newTestStore(t, defaultStoreTimeouts)
+	provider := &countingProvider{stubProvider: stubProvider{name: "stub", err: errors.New("connection refused")}}
+	chain := &ProviderChain{}
+	chain.Add(provider, time.Second)
+	cache := NewQuoteCache(chain, store, 0)
+	poller := NewPoller(cache, []Pair{{From: "USD", To: "BRL"}}, 10*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		poller.Run(ctx)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run deveria parar quando o contexto é cancelado")
+	}
+	// Uma rodada com falha não interrompe as seguintes
+	if calls := provider.calls.Load(); calls < 2 {
+		t.Errorf("%d rodadas antes do cancelamento, esperado ao menos 2", calls)
+	}
+	if n := countQuotes(t, store); n != 0 {
+		t.Errorf("%d cotações registradas com o provedor fora do ar", n)
+	}
+}
+
+func TestGetPolledStaleness(t *testing.T) {
+	usdbrl := Pair{From: "USD", To: "BRL"}
+	interval := time.Minute
+
+	tests := []struct {
+		name  string
+		age   time.Duration
+		stale bool
+	}{
+		{"coletada na última rodada", 30 * time.Second, false},
+		{"uma rodada perdida", 90 * time.Second, false},
+		{"mais de uma rodada perdida", 3 * time.Minute, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTestStore(t, defaultStoreTimeouts)
+			provider := &countingProvider{stubProvider: stubProvider{name: "stub", quote: testQuote(t, "6.0000")}}
+			chain := &ProviderChain{}
+			chain.Add(provider, time.Second)
+			cache := NewQuoteCache(chain, store, 0)
+			cache.Track(usdbrl, interval)
+			cache.put(usdbrl, CachedQuote{Quote: *testQuote(t, "5.1234"), FetchedAt: time.Now().Add(-tt.age)})
+
+			quote, err := cache.Get(usdbrl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if quote.Stale != tt.stale || quote.Quote.Bid.String() != "5.1234" {
+				t.Errorf("cotação %+v, esperado stale = %v com o valor coletado", quote, tt.stale)
+			}
+			// Mesmo desatualizada, a cotação coletada é servida sem chamar os provedores
+			if provider.calls.Load() != 0 {
+				t.Errorf("provedor chamado %d vezes", provider.calls.Load())
+			}
+		})
+	}
+}
+
+func TestGetPolledBeforeFirstRound(t *testing.T) {
+	usdbrl := Pair{From: "USD", To: "BRL"}
+	store := newTestStore(t, defaultStoreTimeouts)
+	provider := &countingProvider{stubProvider: stubProvider{name: "stub", quote: testQuote(t, "5.1234")}}
+	chain := &ProviderChain{}
+	chain.Add(provider, time.Second)
+
+	// Sem nada coletado nem registrado, os provedores são chamados
+	cache := NewQuoteCache(chain, store, 0)
+	cache.Track(usdbrl, time.Minute)
+	if _, err := cache.Get(usdbrl); err != nil {
+		t.Fatal(err)
+	}
+	if provider.calls.Load() != 1 {
+		t.Fatalf("provedor chamado %d vezes, esperado 1", provider.calls.Load())
+	}
+
+	// Depois de reiniciar, a última cotação registrada é servida
+	restarted := NewQuoteCache(chain, store, 0)
+	restarted.Track(usdbrl, time.Minute)
+	quote, err := restarted.Get(usdbrl)
+	if err != nil || quote.Quote.Bid.String() != "5.1234" || quote.Stale {
+		t.Fatalf("deveria servir a cotação registrada: %+v, %v", quote, err)
+	}
+	if provider.calls.Load() != 1 {
+		t.Errorf("provedor chamado de novo com cotação registrada")
+	}
+}
+
+func TestPollerFromEnv(t *testing.T) {
+	cache := NewQuoteCache(&ProviderChain{}, nil, 0)
+	t.Setenv("QUOTE_POLL_PAIRS", "")
+	if poller, err := pollerFromEnv(cache); poller != nil || err != nil {
+		t.Errorf("sem pares não deveria haver Poller: %v, %v", poller, err)
+	}
+
+	t.Setenv("QUOTE_POLL_PAIRS", "usd-brl,EURBRL")
+	t.Setenv("QUOTE_POLL_INTERVAL", "5s")
+	poller, err := pollerFromEnv(cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(poller.pairs) != 2 || poller.pairs[1] != (Pair{From: "EUR", To: "BRL"}) || poller.interval != 5*time.Second {
+		t.Errorf("Poller incorreto: %+v", poller)
+	}
+
+	for _, env := range [][2]string{{"QUOTE_POLL_PAIRS", "USD-BRL,XX"}, {"QUOTE_POLL_INTERVAL", "0s"}} {
+		t.Setenv("QUOTE_POLL_PAIRS", "USD-BRL")
+		t.Setenv("QUOTE_POLL_INTERVAL", "30s")
+		t.Setenv(env[0], env[1])
+		if _, err := pollerFromEnv(cache); err == nil {
+			t.Errorf("%s=%s deveria falhar", env[0], env[1])
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	defaultProviders       = "awesomeapi:200ms"
+	defaultProviderTimeout = 200 * time.Millisecond
+)
+
+type QuoteProvider interface {
+	Name() string
+	GetQuote(ctx context.Context, p Pair) (*Quote, error)
+}
+
+type chainedProvider struct {
+	provider QuoteProvider
+	timeout  time.Duration
+}
+
+// Consulta os provedores em ordem, cada um com o seu timeout, até que um responda
+type ProviderChain struct {
+	providers []chainedProvider
+}
+
+func (c *ProviderChain) Add(provider QuoteProvider, timeout time.Duration) {
+	upstreamTimeouts.WithLabelValues(provider.Name())
+	c.providers = append(c.providers, chainedProvider{provider: provider, timeout: timeout})
+}
+
+func (c *ProviderChain) GetQuote(ctx context.Context, p Pair) (*Quote, error) {
+	var errs []error
+	var names []string
+	notFound := 0
+	timeouts := 0
+	badPayload := false
+	for _, cp := range c.providers {
+		q, err := getQuoteWithTimeout(ctx, cp, p)
+		if err == nil && q.Bid.Sign() <= 0 {
+			err = upstreamError(cp.provider.Name(), ErrUpstreamBadPayload, errors.New("invalid bid "+q.Bid.String()))
+		}
+		if err == nil {
+			q.Provider = cp.provider.Name()
+			return q, nil
+		}
+		if errors.Is(err, errPairNotFound) {
+			notFound++
+			continue
+		}
+		log.Printf("Provider %s failed for %s: %v\n", cp.provider.Name(), p, err)
+		switch upstreamKind(err) {
+		case ErrUpstreamTimeout:
+			timeouts++
+		case ErrUpstreamBadPayload:
+			badPayload = true
+		}
+		names = append(names, cp.provider.Name())
+		errs = append(errs, err)
+	}
+	// Só é "não encontrado" se nenhum provedor conhece o par
+	if notFound == len(c.providers) {
+		return nil, errPairNotFound
+	}
+	var upstream *UpstreamError
+	if len(errs) == 1 && errors.As(errs[0], &upstream) {
+		return nil, upstream
+	}
+	// A falha da cadeia é timeout só se todos que conhecem o par estouraram o prazo
+	kind := ErrUpstreamFailure
+	if timeouts == len(errs) {
+		kind = ErrUpstreamTimeout
+	} else if badPayload {
+		kind = ErrUpstreamBadPayload
+	}
+	return nil, upstreamError(strings.Join(names, ","), kind, errors.Join(errs...))
+}
+
+func getQuoteWithTimeout(ctx context.Context, cp chainedProvider, p Pair) (*Quote, error) {
+	ctx, cancel := context.WithTimeout(ctx, cp.timeout)
+	defer cancel()
+	start := time.Now()
+	q, err := cp.provider.GetQuote(ctx, p)
+	observeUpstream(cp.provider.Name(), start, err)
+	return q, err
+}
+
+// Monta a cadeia a partir de QUOTE_PROVIDERS, ex: "awesomeapi:200ms,ptax:500ms,file"
+func providerChainFromEnv() (*ProviderChain, error) {
+	value := os.Getenv("QUOTE_PROVIDERS")
+	if value == "" {
+		value = defaultProviders
+	}
+	retry, breaker, err := resilienceFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	chain := &ProviderChain{}
+	for _, entry := range strings.Split(value, ",") {
+		name, timeoutValue, hasTimeout := strings.Cut(strings.TrimSpace(entry), ":")
+		timeout := defaultProviderTimeout
+		if hasTimeout {
+			var err error
+			timeout, err = time.ParseDuration(timeoutValue)
+			if err != nil || timeout <= 0 {
+				return nil, errors.New("invalid timeout for provider " + name + ": " + timeoutValue)
+			}
+		}
+		provider, err := newProvider(name, retry, breaker)
+		if err != nil {
+			return nil, err
+		}
+		chain.Add(provider, timeout)
+	}
+	return chain, nil
+}
+
+// Provedores HTTP ganham um circuit breaker próprio, para que a falha de um não desligue o outro
+func newProvider(name string, retry RetryPolicy, breaker BreakerConfig) (QuoteProvider, error) {
+	switch name {
+	case "awesomeapi":
+		return NewAwesomeApiProvider(awesomeApiURL, NewResilientClient(http.DefaultClient, retry, NewCircuitBreaker(name, breaker))), nil
+	case "ptax":
+		return NewPtaxProvider(ptaxURL, NewResilientClient(http.DefaultClient, retry, NewCircuitBreaker(name, breaker))), nil
+	case "file":
+		path := os.Getenv("QUOTE_FILE")
+		if path == "" {
+			return nil, errors.New("QUOTE_FILE is required by the file provider")
+		}
+		return NewFileProvider(path), nil
+	}
+	return nil, errors.New("unknown quote provider: " + name)
+}
+
+func (c *ProviderChain) String() string {
+	names := make([]string, 0, len(c.providers))
+	for _, cp := range c.providers {
+		names = append(names, cp.provider.Name()+":"+cp.timeout.String())
+	}
+	return strings.Join(names, ",")
+}
+
+// Provedor cujas chamadas passam por um circuit breaker
+type breakerProvider interface {
+	Breaker() *CircuitBreaker
+}
+
+// Estado do circuit breaker de cada provedor, na ordem da cadeia
+func (c *ProviderChain) Breakers() []ProviderBreaker {
+	breakers := []ProviderBreaker{}
+	for _, cp := range c.providers {
+		if bp, ok := cp.provider.(breakerProvider); ok {
+			breakers = append(breakers, ProviderBreaker{Provider: cp.provider.Name(), Breaker: bp.Breaker()})
+		}
+	}
+	return breakers
+}
+
+type ProviderBreaker struct {
+	Provider string
+	Breaker  *CircuitBreaker
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestProviderChainFallsBackInOrder(t *testing.T) {
+	usdbrl := Pair{From: "USD", To: "BRL"}
+	quote := testQuote(t, "5.1234")
+	answers := stubProvider{name: "second", quote: quote}
+
+	tests := []struct {
+		name  string
+		first QuoteProvider
+	}{
+		{"primeiro com erro", stubProvider{name: "first", err: errors.New("connection refused")}},
+		{"primeiro estoura o prazo", stubProvider{name: "first", wait: time.Second}},
+		{"primeiro não conhece o par", stubProvider{name: "first", err: errPairNotFound}},
+		{"primeiro devolve bid inválido", stubProvider{name: "first", quote: testQuote(t, "0")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := &ProviderChain{}
+			chain.Add(tt.first, 20*time.Millisecond)
+			chain.Add(answers, time.Second)
+
+			start := time.Now()
+			q, err := chain.GetQuote(context.Background(), usdbrl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if q.Provider != "second" || q.Bid.String() != "5.1234" {
+				t.Errorf("cotação deveria vir do segundo provedor: %+v", q)
+			}
+			// O prazo é de cada provedor: o lento não segura a cadeia
+			if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+				t.Errorf("a cadeia esperou %v pelo primeiro provedor", elapsed)
+			}
+		})
+	}
+}
+
+func TestProviderChainStopsAtFirstAnswer(t *testing.T) {
+	first := &countingProvider{stubProvider: stubProvider{name: "first", quote: testQuote(t, "5.1234")}}
+	second := &countingProvider{stubProvider: stubProvider{name: "second", quote: testQuote(t, "6.1234")}}
+	chain := &ProviderChain{}
+	chain.Add(first, time.Second)
+	chain.Add(second, time.Second)
+
+	q, err := chain.GetQuote(context.Background(), Pair{From: "USD", To: "BRL"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Provider != "first" || first.calls.Load() != 1 || second.calls.Load() != 0 {
+		t.Errorf("só o primeiro deveria ser chamado: %s, chamadas %d e %d", q.Provider, first.calls.Load(), second.calls.Load())
+	}
+}
+
+func TestProviderChainAllFail(t *testing.T) {
+	usdbrl := Pair{From: "USD", To: "BRL"}
+	unknown := stubProvider{name: "unknown", err: errPairNotFound}
+	down := stubProvider{name: "down", err: errors.New("connection refused")}
+	broken := stubProvider{name: "broken", err: upstreamError("broken", ErrUpstreamBadPayload, errors.New("invalid json"))}
+
+	tests := []struct {
+		name      string
+		providers []QuoteProvider
+		notFound  bool
+		failed    string
+	}{
+		{"todos com erro", []QuoteProvider{down, broken}, false, "down,broken"},
+		{"um não conhece o par e outro falha", []QuoteProvider{unknown, down}, false, "down"},
+		{"nenhum conhece o par", []QuoteProvider{unknown, unknown}, true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := &ProviderChain{}
+			for _, p := range tt.providers {
+				chain.Add(p, time.Second)
+			}
+			q, err := chain.GetQuote(context.Background(), usdbrl)
+			if q != nil || err == nil {
+				t.Fatalf("esperado erro, recebido %+v", q)
+			}
+			if errors.Is(err, errPairNotFound) != tt.notFound {
+				t.Fatalf("erro %v: não encontrado = %v, esperado %v", err, errors.Is(err, errPairNotFound), tt.notFound)
+			}
+			var upstream *UpstreamError
+			if !tt.notFound && (!errors.As(err, &upstream) || upstream.Provider != tt.failed) {
+				t.Errorf("erro %v deveria apontar os provedores %q", err, tt.failed)
+			}
+		})
+	}
+}
+
+func TestQuoteResponseReportsProvider(t *testing.T) {
+	store := newTestStore(t, defaultStoreTimeouts)
+	chain := &ProviderChain{}
+	chain.Add(stubProvider{name: "awesomeapi", err: errors.New("connection refused")}, time.Second)
+	chain.Add(stubProvider{name: "ptax", quote: testQuote(t, "5.1234")}, time.Second)
+	server := NewServer(":0", "", store, NewQuoteCache(chain, store, time.Minute), nil, NewAlertEvaluator(nil), nil)
+
+	rec := httptest.NewRecorder()
+	server.http.Handler.ServeHTTP(rec, httptest.NewRequest("GET", "/quotes/USD-BRL", nil))
+	var body QuoteResponse
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusOK || body.Provider != "ptax" {
+		t.Errorf("resposta deveria indicar o provedor que respondeu: %d %+v", rec.Code, body)
+	}
+	stored, err := store.LatestQuote(Pair{From: "USD", To: "BRL"})
+	if err != nil || stored.Provider.String != "ptax" {
+		t.Errorf("cotação registrada sem o provedor: %+v, %v", stored, err)
+	}
+}
+
+func TestProviderChainFromEnv(t *testing.T) {
+	t.Setenv("QUOTE_PROVIDERS", "awesomeapi:300ms, ptax")
+	chain, err := providerChainFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := chain.String(); got != "awesomeapi:300ms,ptax:200ms" {
+		t.Errorf("cadeia = %s", got)
+	}
+
+	for _, value := range []string{"awesomeapi:0s", "awesomeapi:soon", "bcb"} {
+		t.Setenv("QUOTE_PROVIDERS", value)
+		if _, err := providerChainFromEnv(); err == nil {
+			t.Errorf("QUOTE_PROVIDERS=%s deveria falhar", value)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/isaacmirandacampos/go-expert/quotation-api/internal/decimal"
+)
+
+const ptaxURL = "https://olinda.bcb.gov.br/olinda/servico/PTAX/versao/v1/odata/"
+
+// Boletins PTAX são publicados no horário de Brasília, que não tem mais horário de verão
+var brasilia = time.FixedZone("BRT", -3*60*60)
+
+// Cotações PTAX do Banco Central. Só existem contra o real, e apenas em dias
+// úteis, então é buscado o último boletim da última semana.
+type PtaxProvider struct {
+	url    string
+	client *ResilientClient
+}
+
+type ptaxResponse struct {
+	Value []struct {
+		CotacaoCompra   decimal.Decimal `json:"cotacaoCompra"`
+		CotacaoVenda    decimal.Decimal `json:"cotacaoVenda"`
+		DataHoraCotacao string          `json:"dataHoraCotacao"`
+		TipoBoletim     string          `json:"tipoBoletim"`
+	} `json:"value"`
+}
+
+func NewPtaxProvider(url string, client *ResilientClient) *PtaxProvider {
+	return &PtaxProvider{url: url, client: client}
+}
+
+func (pp *PtaxProvider) Breaker() *CircuitBreaker {
+	return pp.client.Breaker()
+}
+
+func (pp *PtaxProvider) Name() string {
+	return "ptax"
+}
+
+func (pp *PtaxProvider) GetQuote(ctx context.Context, p Pair) (*Quote, error) {
+	if p.To != "BRL" {
+		return nil, errPairNotFound
+	}
+	now := time.Now().In(brasilia)
+	url := fmt.Sprintf(
+		"%sCotacaoMoedaPeriodo(moeda=@moeda,dataInicial=@dataInicial,dataFinalCotacao=@dataFinalCotacao)"+
+			"?@moeda='%s'&@dataInicial='%s'&@dataFinalCotacao='%s'&$top=1&$orderby=dataHoraCotacao%%20desc&$format=json",
+		pp.url, p.From, now.AddDate(0, 0, -7).Format("01-02-2006"), now.Format("01-02-2006"),
+	)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, upstreamError(pp.Name(), ErrUpstreamFailure, fmt.Errorf("error creating request: %w", err))
+	}
+
+	resp, err := pp.client.Do(req)
+	if err != nil {
+		if errors.Is(err, ErrCircuitOpen) {
+			return nil, upstreamError(pp.Name(), ErrUpstreamFailure, err)
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			log.Println("Request Timeout olinda.bcb.gov.br")
+			return nil, upstreamError(pp.Name(), ErrUpstreamTimeout, fmt.Errorf("request to olinda.bcb.gov.br: %w", err))
+		}
+		return nil, upstreamError(pp.Name(), ErrUpstreamFailure, fmt.Errorf("error executing request: %w", err))
+	}
+	defer resp.Body.Close()
+
+	// Moedas desconhecidas são respondidas com erro de validação
+	if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusNotFound {
+		return nil, errPairNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, upstreamError(pp.Name(), ErrUpstreamFailure, errors.New("unexpected status from olinda.bcb.gov.br: "+resp.Status))
+	}
+
+	res, err := io.ReadAll(resp.Body)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			log.Println("Request Timeout olinda.bcb.gov.br")
+			return nil, upstreamError(pp.Name(), ErrUpstreamTimeout, fmt.Errorf("reading response: %w", err))
+		}
+		return nil, upstreamError(pp.Name(), ErrUpstreamFailure, fmt.Errorf("error reading response: %w", err))
+	}
+
+	var body ptaxResponse
+	err = json.Unmarshal(res, &body)
+	if err != nil {
+		return nil, upstreamError(pp.Name(), ErrUpstreamBadPayload, fmt.Errorf("error unmarshalling response: %w", err))
+	}
+	if len(body.Value) == 0 {
+		return nil, errPairNotFound
+	}
+	bulletin := body.Value[0]
+	quotedAt, err := time.ParseInLocation("2006-01-02 15:04:05.999", bulletin.DataHoraCotacao, brasilia)
+	if err != nil {
+		return nil, upstreamError(pp.Name(), ErrUpstreamBadPayload, fmt.Errorf("error parsing dataHoraCotacao: %w", err))
+	}
+	return &Quote{
+		Code:       p.From,
+		Codein:     p.To,
+		Name:       "PTAX " + bulletin.TipoBoletim,
+		Bid:        bulletin.CotacaoCompra,
+		Ask:        decimal.NullDecimal{Decimal: bulletin.CotacaoVenda, Valid: true},
+		Timestamp:  strconv.FormatInt(quotedAt.Unix(), 10),
+		CreateDate: quotedAt.Format(time.DateTime),
+	}, nil
+}
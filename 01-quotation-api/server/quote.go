@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/isaacmirandacampos/go-expert/quotation-api/internal/decimal"
+)
+
+var (
+	errInvalidPair  = errors.New("invalid currency pair")
+	errPairNotFound = errors.New("currency pair not found")
+)
+
+type Pair struct {
+	From string
+	To   string
+}
+
+// Aceita "USD-BRL", "usd-brl" ou "USDBRL"
+func parsePair(s string) (Pair, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	from, to, found := strings.Cut(s, "-")
+	if !found {
+		if len(s) != 6 {
+			return Pair{}, errInvalidPair
+		}
+		from, to = s[:3], s[3:]
+	}
+	if !isCurrencyCode(from) || !isCurrencyCode(to) || from == to {
+		return Pair{}, errInvalidPair
+	}
+	return Pair{From: from, To: to}, nil
+}
+
+func isCurrencyCode(s string) bool {
+	if len(s) < 3 || len(s) > 5 {
+		return false
+	}
+	for _, r := range s {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+func (p Pair) String() string {
+	return p.From + "-" + p.To
+}
+
+// Chave usada pela awesomeapi no corpo da resposta, ex: USDBRL
+func (p Pair) key() string {
+	return p.From + p.To
+}
+
+type Quote struct {
+	Code       string              `json:"code"`
+	Codein     string              `json:"codein"`
+	Name       string              `json:"name"`
+	High       decimal.NullDecimal `json:"high"`
+	Low        decimal.NullDecimal `json:"low"`
+	Bid        decimal.Decimal     `json:"bid"`
+	Ask        decimal.NullDecimal `json:"ask"`
+	Timestamp  string              `json:"timestamp"`
+	CreateDate string              `json:"create_date"`
+	Provider   string              `json:"-"`
+}
+
+type QuoteResponse struct {
+	Pair      string              `json:"pair"`
+	Name      string              `json:"name"`
+	Bid       decimal.Decimal     `json:"bid"`
+	Ask       decimal.NullDecimal `json:"ask"`
+	High      decimal.NullDecimal `json:"high"`
+	Low       decimal.NullDecimal `json:"low"`
+	Timestamp string              `json:"timestamp"`
+	Provider  string              `json:"provider"`
+	Stale     bool                `json:"stale"`
+	Age       int64               `json:"age"`
+}
+
+func newQuoteResponse(p Pair, c CachedQuote) QuoteResponse {
+	return QuoteResponse{
+		Pair:      p.String(),
+		Name:      c.Quote.Name,
+		Bid:       c.Quote.Bid,
+		Ask:       c.Quote.Ask,
+		High:      c.Quote.High,
+		Low:       c.Quote.Low,
+		Timestamp: c.Quote.Timestamp,
+		Provider:  c.Quote.Provider,
+		Stale:     c.Stale,
+		Age:       int64(c.Age().Seconds()),
+	}
+}
+
+// A awesomeapi devolve a cotação sob uma chave dinâmica, ex: {"EURBRL": {...}}.
+// Só a cotação do par é decodificada, e preços que não são números são rejeitados.
+func decodeQuote(body []byte, p Pair) (*Quote, error) {
+	var quotes map[string]json.RawMessage
+	err := json.Unmarshal(body, &quotes)
+	if err != nil {
+		return nil, errors.New("error unmarshalling response")
+	}
+	raw, ok := quotes[p.key()]
+	if !ok {
+		return nil, errPairNotFound
+	}
+	var quote Quote
+	err = json.Unmarshal(raw, &quote)
+	if err != nil {
+		return nil, errors.New("invalid " + p.String() + " quote: " + err.Error())
+	}
+	return &quote, nil
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRetryAttempts   = 3
+	defaultRetryBackoff    = 25 * time.Millisecond
+	defaultRetryMaxBackoff = 500 * time.Millisecond
+	defaultBreakerFailures = 5
+	defaultBreakerCooldown = 30 * time.Second
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// Tentativas de uma chamada: a espera entre elas dobra a partir de Backoff,
+// até MaxBackoff, com jitter para que clientes não tentem em sincronia.
+type RetryPolicy struct {
+	Attempts   int
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// Espera antes da tentativa seguinte a attempt (1, 2, ...): metade fixa e
+// metade aleatória do backoff exponencial
+func (p RetryPolicy) wait(attempt int) time.Duration {
+	d := p.Backoff << (attempt - 1)
+	if d > p.MaxBackoff || d <= 0 {
+		d = p.MaxBackoff
+	}
+	half := d / 2
+	return half + rand.N(half+1)
+}
+
+type BreakerConfig struct {
+	// Falhas consecutivas que abrem o circuito
+	Failures int
+	// Tempo aberto até que uma chamada de teste seja permitida
+	Cooldown time.Duration
+}
+
+// Lê QUOTE_RETRY_ATTEMPTS, QUOTE_RETRY_BACKOFF, QUOTE_BREAKER_FAILURES e QUOTE_BREAKER_COOLDOWN
+func resilienceFromEnv() (RetryPolicy, BreakerConfig, error) {
+	retry := RetryPolicy{Attempts: defaultRetryAttempts, Backoff: defaultRetryBackoff, MaxBackoff: defaultRetryMaxBackoff}
+	breaker := BreakerConfig{Failures: defaultBreakerFailures, Cooldown: defaultBreakerCooldown}
+	var err error
+	if value := os.Getenv("QUOTE_RETRY_ATTEMPTS"); value != "" {
+		retry.Attempts, err = strconv.Atoi(value)
+		if err != nil || retry.Attempts <= 0 {
+			return retry, breaker, errors.New("invalid QUOTE_RETRY_ATTEMPTS: " + value)
+		}
+	}
+	if value := os.Getenv("QUOTE_RETRY_BACKOFF"); value != "" {
+		retry.Backoff, err = time.ParseDuration(value)
+		if err != nil || retry.Backoff <= 0 {
+			return retry, breaker, errors.New("invalid QUOTE_RETRY_BACKOFF: " + value)
+		}
+	}
+	if value := os.Getenv("QUOTE_BREAKER_FAILURES"); value != "" {
+		breaker.Failures, err = strconv.Atoi(value)
+		if err != nil || breaker.Failures <= 0 {
+			return retry, breaker, errors.New("invalid QUOTE_BREAKER_FAILURES: " + value)
+		}
+	}
+	if value := os.Getenv("QUOTE_BREAKER_COOLDOWN"); value != "" {
+		breaker.Cooldown, err = time.ParseDuration(value)
+		if err != nil || breaker.Cooldown <= 0 {
+			return retry, breaker, errors.New("invalid QUOTE_BREAKER_COOLDOWN: " + value)
+		}
+	}
+	return retry, breaker, nil
+}
+
+type BreakerState int
+
+const (
+	BreakerClosed BreakerState = iota
+	BreakerOpen
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// Abre depois de Failures falhas consecutivas e recusa as chamadas durante o
+// Cooldown. Depois dele, uma única chamada de teste (half-open) decide se o
+// circuito fecha ou volta a abrir.
+type CircuitBreaker struct {
+	name   string
+	config BreakerConfig
+	now    func() time.Time
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func NewCircuitBreaker(name string, config BreakerConfig) *CircuitBreaker {
+	b := &CircuitBreaker{name: name, config: config, now: time.Now}
+	breakerState.WithLabelValues(name).Set(float64(BreakerClosed))
+	return b
+}
+
+// Informa se a chamada pode ser feita; toda chamada permitida deve ter o resultado registrado em Record
+func (b *CircuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) < b.config.Cooldown {
+			return false
+		}
+		b.transition(BreakerHalfOpen)
+		b.probing = true
+		return true
+	case BreakerHalfOpen:
+		// Só uma chamada de teste por vez
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+func (b *CircuitBreaker) Record(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	if success {
+		b.failures = 0
+		b.transition(BreakerClosed)
+		return
+	}
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= b.config.Failures {
+		b.openedAt = b.now()
+		b.transition(BreakerOpen)
+	}
+}
+
+func (b *CircuitBreaker) transition(state BreakerState) {
+	if b.state == state && state != BreakerOpen {
+		return
+	}
+	b.state = state
+	breakerState.WithLabelValues(b.name).Set(float64(state))
+}
+
+type BreakerStatus struct {
+	State               BreakerState
+	ConsecutiveFailures int
+	OpenedAt            time.Time
+}
+
+func (b *CircuitBreaker) Status() BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	status := BreakerStatus{State: b.state, ConsecutiveFailures: b.failures}
+	if b.state != BreakerClosed {
+		status.OpenedAt = b.openedAt
+	}
+	return status
+}
+
+// Cliente HTTP com novas tentativas e circuit breaker, usado pelos provedores.
+// As tentativas acontecem dentro do prazo do contexto da requisição: quando
+// não há tempo para esperar o backoff, a última resposta é devolvida.
+type ResilientClient struct {
+	client  *http.Client
+	retry   RetryPolicy
+	breaker *CircuitBreaker
+}
+
+func NewResilientClient(client *http.Client, retry RetryPolicy, breaker *CircuitBreaker) *ResilientClient {
+	return &ResilientClient{client: client, retry: retry, breaker: breaker}
+}
+
+func (c *ResilientClient) Breaker() *CircuitBreaker {
+	return c.breaker
+}
+
+// Erros de conexão, 5xx e 429 são tentados de novo; as demais respostas são
+// devolvidas como vieram. Com o circuito aberto, devolve ErrCircuitOpen sem
+// fazer a requisição.
+func (c *ResilientClient) Do(req *http.Request) (*http.Response, error) {
+	if !c.breaker.Allow() {
+		return nil, ErrCircuitOpen
+	}
+	ctx := req.Context()
+	// Sem GetBody o corpo não pode ser enviado de novo
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	attemptReq := req
+	var resp *http.Response
+	var err error
+	for attempt := 1; ; attempt++ {
+		resp, err = c.client.Do(attemptReq)
+		if !failed(resp, err) || !replayable || ctx.Err() != nil || attempt >= c.retry.Attempts {
+			break
+		}
+		wait := c.retry.wait(attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= wait {
+			break
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			c.breaker.Record(false)
+			return resp, err
+		case <-timer.C:
+		}
+		next := req
+		if req.GetBody != nil {
+			next = req.Clone(ctx)
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				// Fica a resposta da última tentativa
+				break
+			}
+			next.Body = body
+		}
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		attemptReq = next
+	}
+	c.breaker.Record(!failed(resp, err))
+	return resp, err
+}
+
+// Falhas contam para o circuit breaker e são tentadas de novo; respostas 4xx
+// (exceto 429) mostram que o provedor está de pé
+func failed(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests
+}
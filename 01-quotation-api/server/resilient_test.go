@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var testRetry = RetryPolicy{Attempts: 3, Backoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+
+// Servidor que responde com os status em ordem, repetindo o último, e conta as chamadas
+func newFlakyServer(t *testing.T, delay time.Duration, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1))
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		w.WriteHeader(statuses[min(n, len(statuses))-1])
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func doGet(t *testing.T, ctx context.Context, client *ResilientClient, url string) (int, error) {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+func TestResilientClientRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		status   int
+		calls    int32
+		failures int
+	}{
+		{"erro do servidor seguido de sucesso", []int{500, 503, 200}, 200, 3, 0},
+		{"429 é tentado de novo", []int{429, 200}, 200, 2, 0},
+		{"tentativas esgotadas", []int{502}, 502, 3, 1},
+		{"erro do cliente não é tentado de novo", []int{404}, 404, 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, calls := newFlakyServer(t, 0, tt.statuses...)
+			client := NewResilientClient(server.Client(), testRetry, NewCircuitBreaker("test", BreakerConfig{Failures: 5, Cooldown: time.Minute}))
+
+			status, err := doGet(t, context.Background(), client, server.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if status != tt.status || calls.Load() != tt.calls {
+				t.Errorf("status %d após %d chamadas, esperado %d após %d", status, calls.Load(), tt.status, tt.calls)
+			}
+			if failures := client.Breaker().Status().ConsecutiveFailures; failures != tt.failures {
+				t.Errorf("falhas consecutivas = %d, esperado %d", failures, tt.failures)
+			}
+		})
+	}
+}
+
+func TestResilientClientStaysWithinDeadline(t *testing.T) {
+	server, calls := newFlakyServer(t, 30*time.Millisecond, 500)
+	retry := RetryPolicy{Attempts: 10, Backoff: 10 * time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+	client := NewResilientClient(server.Client(), retry, NewCircuitBreaker("test", BreakerConfig{Failures: 5, Cooldown: time.Minute}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	doGet(t, ctx, client, server.URL)
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("as tentativas passaram do prazo: %v", elapsed)
+	}
+	if calls.Load() > 2 {
+		t.Errorf("%d chamadas não cabem no prazo", calls.Load())
+	}
+}
+
+func TestCircuitBreakerOpensAndRecovers(t *testing.T) {
+	server, calls := newFlakyServer(t, 0, 500, 500, 200)
+	breaker := NewCircuitBreaker("test", BreakerConfig{Failures: 2, Cooldown: time.Minute})
+	now := time.Now()
+	breaker.now = func() time.Time { return now }
+	client := NewResilientClient(server.Client(), RetryPolicy{Attempts: 1}, breaker)
+
+	for i := 0; i < 2; i++ {
+		if status, err := doGet(t, context.Background(), client, server.URL); err != nil || status != 500 {
+			t.Fatalf("chamada %d: status %d, erro %v", i+1, status, err)
+		}
+	}
+	if status := breaker.Status(); status.State != BreakerOpen || !status.OpenedAt.Equal(now) {
+		t.Fatalf("circuito deveria abrir após 2 falhas: %+v", status)
+	}
+
+	// Aberto, nem chega ao servidor
+	if _, err := doGet(t, context.Background(), client, server.URL); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("esperado ErrCircuitOpen, recebido %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("chamadas com o circuito aberto: %d", calls.Load()-2)
+	}
+
+	// Após o cooldown, uma chamada de teste por vez
+	now = now.Add(time.Minute)
+	if !breaker.Allow() {
+		t.Fatal("chamada de teste deveria ser permitida após o cooldown")
+	}
+	if breaker.Allow() || breaker.Status().State != BreakerHalfOpen {
+		t.Fatal("só uma chamada de teste deveria ser permitida")
+	}
+	breaker.Record(false)
+	if breaker.Status().State != BreakerOpen {
+		t.Fatal("falha na chamada de teste deveria reabrir o circuito")
+	}
+
+	now = now.Add(time.Minute)
+	if status, err := doGet(t, context.Background(), client, server.URL); err != nil || status != 200 {
+		t.Fatalf("chamada de teste: status %d, erro %v", status, err)
+	}
+	if status := breaker.Status(); status.State != BreakerClosed || status.ConsecutiveFailures != 0 {
+		t.Errorf("circuito deveria fechar após sucesso: %+v", status)
+	}
+}
+
+func TestOpenCircuitServesLastStoredQuote(t *testing.T) {
+	upstream, calls := newFlakyServer(t, 0, 500)
+	store := newTestStore(t, defaultStoreTimeouts)
+	usdbrl := Pair{From: "USD", To: "BRL"}
+	if err := store.SaveQuote(usdbrl, testQuote(t, "5.1234")); err != nil {
+		t.Fatal(err)
+	}
+	breaker := NewCircuitBreaker("awesomeapi", BreakerConfig{Failures: 1, Cooldown: time.Minute})
+	chain := &ProviderChain{}
+	chain.Add(NewAwesomeApiProvider(upstream.URL+"/", NewResilientClient(upstream.Client(), RetryPolicy{Attempts: 1}, breaker)), time.Second)
+	cache := NewQuoteCache(chain, store, 0)
+	server := NewServer(":0", "", store, cache, nil, NewAlertEvaluator(nil), nil)
+
+	for i := 0; i < 3; i++ {
+		quote, err := cache.Get(usdbrl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !quote.Stale || quote.Quote.Bid.String() != "5.1234" {
+			t.Errorf("deveria servir a última cotação registrada: %+v", quote)
+		}
+		cache.Wait()
+	}
+	if calls.Load() != 1 {
+		t.Errorf("com o circuito aberto o provedor foi chamado %d vezes", calls.Load())
+	}
+
+	rec := httptest.NewRecorder()
+	server.http.Handler.ServeHTTP(rec, httptest.NewRequest("GET", "/health", nil))
+	var health healthResponse
+	if err := json.NewDecoder(rec.Body).Decode(&health); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusOK || health.Status != "degraded" || len(health.Providers) != 1 {
+		t.Fatalf("health incorreto: %d %+v", rec.Code, health)
+	}
+	if p := health.Providers[0]; p.Name != "awesomeapi" || p.Breaker != "open" || p.ConsecutiveFailures != 1 || p.OpenedAt == "" {
+		t.Errorf("estado do provedor incorreto: %+v", p)
+	}
+}
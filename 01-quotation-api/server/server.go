@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+)
+
+const (
+	readHeaderTimeout = 5 * time.Second
+	readTimeout       = 10 * time.Second
+	writeTimeout      = 30 * time.Second
+	idleTimeout       = 2 * time.Minute
+	shutdownTimeout   = 15 * time.Second
+)
+
+// Reúne o banco, o cache e os processos em segundo plano do servidor
+type Server struct {
+	store  *Store
+	cache  *QuoteCache
+	poller *Poller
+	alerts *AlertEvaluator
+	broker *QuoteBroker
+	// Vagas de exportação (ver exportSlots)
+	exports chan struct{}
+	http    *http.Server
+	// nil com o gRPC desligado
+	grpc     *grpc.Server
+	grpcAddr string
+}
+
+// Com auth nil as rotas ficam abertas; com grpcAddr vazio o gRPC fica desligado
+func NewServer(addr, grpcAddr string, store *Store, cache *QuoteCache, poller *Poller, alerts *AlertEvaluator, auth *Authenticator) *Server {
+	s := &Server{
+		store:   store,
+		cache:   cache,
+		poller:  poller,
+		alerts:  alerts,
+		broker:  NewQuoteBroker(),
+		exports: make(chan struct{}, exportSlots(store)),
+	}
+	store.OnQuoteStored(s.broker.Publish)
+	api := s.routes()
+	var handler http.Handler = api
+	if auth != nil {
+		handler = auth.Wrap(handler)
+	}
+	// /metrics e /health ficam fora da autenticação, para o scraper do
+	// Prometheus e as verificações do balanceador
+	root := http.NewServeMux()
+	root.Handle("GET /metrics", promhttp.Handler())
+	root.HandleFunc("GET /health", s.healthHandler)
+	root.Handle("/", handler)
+	route := func(r *http.Request) string {
+		if _, pattern := root.Handler(r); pattern != "/" {
+			return muxRoute(root, r)
+		}
+		return muxRoute(api, r)
+	}
+	s.http = &http.Server{
+		Addr:              addr,
+		Handler:           instrument(route, root),
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       readTimeout,
+		WriteTimeout:      writeTimeout,
+		IdleTimeout:       idleTimeout,
+	}
+	// Streams não terminam sozinhos; fechá-los permite que o Shutdown conclua
+	s.http.RegisterOnShutdown(s.broker.Close)
+	if grpcAddr != "" {
+		s.grpc = newGRPCServer(s, auth)
+		s.grpcAddr = grpcAddr
+	}
+	return s
+}
+
+func (s *Server) routes() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/usd-to-brl", s.handler)
+	mux.HandleFunc("GET /quotes/history", s.historyHandler)
+	mux.HandleFunc("GET /quotes/stream", s.streamHandler)
+	mux.HandleFunc("GET /quotes/export", s.exportHandler)
+	mux.HandleFunc("GET /convert", s.convertHandler)
+	mux.HandleFunc("GET /quotes/{pair}", s.quoteHandler)
+	mux.HandleFunc("GET /quotes/{pair}/ohlc", s.ohlcHandler)
+	mux.HandleFunc("GET /quotes/{pair}/stats", s.statsHandler)
+	mux.HandleFunc("POST /alerts", s.createAlertHandler)
+	mux.HandleFunc("GET /alerts", s.listAlertsHandler)
+	mux.HandleFunc("GET /alerts/{id}", s.getAlertHandler)
+	mux.HandleFunc("DELETE /alerts/{id}", s.deleteAlertHandler)
+	return mux
+}
+
+// Atende até o contexto ser cancelado e então desliga na ordem inversa da
+// dependência: para de aceitar conexões e espera as requisições em andamento,
+// para a coleta periódica, espera as escritas pendentes do cache, esvazia a
+// fila de alertas e só então fecha o banco.
+func (s *Server) Run(ctx context.Context) error {
+	polling, stopPolling := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	if s.poller != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.poller.Run(polling)
+		}()
+	}
+	// Os alertas param por último, pois as escritas pendentes ainda os alimentam
+	evaluating, stopEvaluating := context.WithCancel(context.Background())
+	alertsDone := make(chan struct{})
+	go func() {
+		defer close(alertsDone)
+		s.alerts.Run(evaluating)
+	}()
+
+	serveErr := make(chan error, 2)
+	go func() {
+		log.Default().Println("Running on " + s.http.Addr + "...")
+		serveErr <- s.http.ListenAndServe()
+	}()
+	if s.grpc != nil {
+		go func() {
+			listener, err := net.Listen("tcp", s.grpcAddr)
+			if err != nil {
+				serveErr <- err
+				return
+			}
+			log.Default().Println("gRPC running on " + s.grpcAddr + "...")
+			if err = s.grpc.Serve(listener); err != nil {
+				serveErr <- err
+			}
+		}()
+	}
+
+	var err error
+	select {
+	case err = <-serveErr:
+	case <-ctx.Done():
+		log.Default().Println("Shutting down...")
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if shutdownErr := s.http.Shutdown(shutdownCtx); shutdownErr != nil {
+		log.Println("Error draining requests:", shutdownErr)
+	}
+	// O broker já foi fechado pelo Shutdown, então os WatchQuotes terminam
+	if s.grpc != nil {
+		stopped := make(chan struct{})
+		go func() {
+			s.grpc.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-shutdownCtx.Done():
+			s.grpc.Stop()
+		}
+	}
+	stopPolling()
+	wg.Wait()
+	s.cache.Wait()
+	stopEvaluating()
+	<-alertsDone
+	if waitErr := s.alerts.Wait(shutdownCtx); waitErr != nil {
+		log.Println("Pending alert deliveries abandoned:", waitErr)
+	}
+	if closeErr := s.store.Close(); closeErr != nil {
+		log.Println("Error closing database:", closeErr)
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
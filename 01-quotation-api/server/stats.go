@@ -0,0 +1,272 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"math/big"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/isaacmirandacampos/go-expert/quotation-api/internal/decimal"
+)
+
+const (
+	defaultStatsWindow = 24 * time.Hour
+	maxCandles         = 5000
+)
+
+type Candle struct {
+	Start string          `json:"start"`
+	Open  decimal.Decimal `json:"open"`
+	High  decimal.Decimal `json:"high"`
+	Low   decimal.Decimal `json:"low"`
+	Close decimal.Decimal `json:"close"`
+	Count int             `json:"count"`
+}
+
+type OHLCResponse struct {
+	Pair     string   `json:"pair"`
+	Interval string   `json:"interval"`
+	From     string   `json:"from"`
+	To       string   `json:"to"`
+	Candles  []Candle `json:"candles"`
+}
+
+type StatsResponse struct {
+	Pair   string              `json:"pair"`
+	From   string              `json:"from"`
+	To     string              `json:"to"`
+	Count  int                 `json:"count"`
+	Min    decimal.NullDecimal `json:"min"`
+	Max    decimal.NullDecimal `json:"max"`
+	Mean   decimal.NullDecimal `json:"mean"`
+	Stddev decimal.NullDecimal `json:"stddev"`
+	First  decimal.NullDecimal `json:"first"`
+	Last   decimal.NullDecimal `json:"last"`
+	Change string              `json:"change,omitempty"`
+}
+
+// Percorre os bids registrados do par entre from e to (inclusive), em ordem de inserção
+func (s *Store) EachBid(p Pair, from, to time.Time, fn func(insertedAt int64, bid decimal.Decimal)) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Stats)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT inserted_at, bid FROM quotes WHERE pair = ? AND bid IS NOT NULL AND inserted_at >= ? AND inserted_at <= ? ORDER BY inserted_at, rowid`,
+		p.String(), from.Unix(), to.Unix(),
+	)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			log.Println("Query Timeout")
+		}
+		return databaseError(ctx, "query", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var insertedAt int64
+		var bid decimal.Decimal
+		if err = rows.Scan(&insertedAt, &bid); err != nil {
+			return databaseError(ctx, "scan", err)
+		}
+		fn(insertedAt, bid)
+	}
+	if err = rows.Err(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			log.Println("Query Timeout")
+		}
+		return databaseError(ctx, "query", err)
+	}
+	return nil
+}
+
+// Agrupa os bids em candles alinhados a múltiplos do intervalo desde a época (UTC)
+type candleBuilder struct {
+	interval int64
+	candles  []Candle
+	start    int64
+}
+
+func (b *candleBuilder) add(insertedAt int64, bid decimal.Decimal) {
+	start := insertedAt - insertedAt%b.interval
+	if len(b.candles) == 0 || start != b.start {
+		b.start = start
+		b.candles = append(b.candles, Candle{
+			Start: time.Unix(start, 0).UTC().Format(time.RFC3339),
+			Open:  bid,
+			High:  bid,
+			Low:   bid,
+			Close: bid,
+			Count: 1,
+		})
+		return
+	}
+	c := &b.candles[len(b.candles)-1]
+	if bid.Cmp(c.High) > 0 {
+		c.High = bid
+	}
+	if bid.Cmp(c.Low) < 0 {
+		c.Low = bid
+	}
+	c.Close = bid
+	c.Count++
+}
+
+// Estatísticas em uma única passada: a média é exata (soma das unidades) e o
+// desvio padrão populacional usa o algoritmo de Welford.
+type statsAccumulator struct {
+	count       int
+	min, max    decimal.Decimal
+	first, last decimal.Decimal
+	sum         big.Int
+	mean, m2    float64
+}
+
+func (a *statsAccumulator) add(_ int64, bid decimal.Decimal) {
+	a.count++
+	if a.count == 1 {
+		a.min, a.max, a.first = bid, bid, bid
+	}
+	if bid.Cmp(a.min) < 0 {
+		a.min = bid
+	}
+	if bid.Cmp(a.max) > 0 {
+		a.max = bid
+	}
+	a.last = bid
+	a.sum.Add(&a.sum, big.NewInt(bid.Units()))
+	x := float64(bid.Units())
+	delta := x - a.mean
+	a.mean += delta / float64(a.count)
+	a.m2 += delta * (x - a.mean)
+}
+
+func (a *statsAccumulator) result(response *StatsResponse) error {
+	response.Count = a.count
+	if a.count == 0 {
+		return nil
+	}
+	// Média em unidades, arredondada com empate afastando do zero
+	meanUnits, err := strconv.ParseInt(new(big.Rat).SetFrac(&a.sum, big.NewInt(int64(a.count))).FloatString(0), 10, 64)
+	if err != nil {
+		return errors.New("mean out of range")
+	}
+	mean := decimal.FromUnits(meanUnits)
+	stddev := decimal.FromUnits(int64(math.Round(math.Sqrt(a.m2 / float64(a.count)))))
+	response.Min = decimal.NullDecimal{Decimal: a.min, Valid: true}
+	response.Max = decimal.NullDecimal{Decimal: a.max, Valid: true}
+	response.Mean = decimal.NullDecimal{Decimal: mean, Valid: true}
+	response.Stddev = decimal.NullDecimal{Decimal: stddev, Valid: true}
+	response.First = decimal.NullDecimal{Decimal: a.first, Valid: true}
+	response.Last = decimal.NullDecimal{Decimal: a.last, Valid: true}
+	if a.first.Sign() > 0 {
+		// (last - first) / first * 100
+		change := new(big.Rat).Sub(a.last.Rat(), a.first.Rat())
+		change.Quo(change, a.first.Rat())
+		change.Mul(change, big.NewRat(100, 1))
+		response.Change = change.FloatString(4)
+	}
+	return nil
+}
+
+// Janela [from, to]; sem parâmetros, as últimas 24h. `window` define from a partir de to.
+func parseStatsWindow(r *http.Request) (time.Time, time.Time, error) {
+	query := r.URL.Query()
+	to := time.Now()
+	var err error
+	if value := query.Get("to"); value != "" {
+		to, err = parseEndTime(value)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("invalid to")
+		}
+	}
+	window := defaultStatsWindow
+	if value := query.Get("window"); value != "" {
+		window, err = time.ParseDuration(value)
+		if err != nil || window <= 0 {
+			return time.Time{}, time.Time{}, errors.New("invalid window")
+		}
+	}
+	from := to.Add(-window)
+	if value := query.Get("from"); value != "" {
+		from, err = parseTime(value)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("invalid from")
+		}
+	}
+	if from.After(to) {
+		return time.Time{}, time.Time{}, errors.New("from must not be after to")
+	}
+	return from, to, nil
+}
+
+func (s *Server) ohlcHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	pair, err := parsePair(r.PathValue("pair"))
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	interval := time.Hour
+	if value := r.URL.Query().Get("interval"); value != "" {
+		interval, err = time.ParseDuration(value)
+		if err != nil || interval < time.Second || interval%time.Second != 0 {
+			writeProblem(w, r, http.StatusBadRequest, "invalid interval")
+			return
+		}
+	}
+	from, to, err := parseStatsWindow(r)
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if to.Sub(from)/interval >= maxCandles {
+		writeProblem(w, r, http.StatusBadRequest, "too many candles, use a larger interval or a shorter window")
+		return
+	}
+	builder := candleBuilder{interval: int64(interval.Seconds()), candles: []Candle{}}
+	if err = s.store.EachBid(pair, from, to, builder.add); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	json.NewEncoder(w).Encode(OHLCResponse{
+		Pair:     pair.String(),
+		Interval: interval.String(),
+		From:     from.UTC().Format(time.RFC3339),
+		To:       to.UTC().Format(time.RFC3339),
+		Candles:  builder.candles,
+	})
+}
+
+func (s *Server) statsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	pair, err := parsePair(r.PathValue("pair"))
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	from, to, err := parseStatsWindow(r)
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	var stats statsAccumulator
+	if err = s.store.EachBid(pair, from, to, stats.add); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	response := StatsResponse{
+		Pair: pair.String(),
+		From: from.UTC().Format(time.RFC3339),
+		To:   to.UTC().Format(time.RFC3339),
+	}
+	if err = stats.result(&response); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	json.NewEncoder(w).Encode(response)
+}
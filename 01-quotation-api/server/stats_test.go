@@ -0,0 +1,64 @@
+package main
+
+import "testing"
+
+func TestCandleBuilder(t *testing.T) {
+	builder := candleBuilder{interval: 3600}
+	// 2024-01-01T00:00:00Z = 1704067200
+	points := []struct {
+		at  int64
+		bid string
+	}{
+		{1704067200, "5.00"},
+		{1704067200 + 600, "5.20"},
+		{1704067200 + 1200, "4.90"},
+		{1704067200 + 3599, "5.10"},
+		{1704067200 + 7200, "5.30"},
+	}
+	for _, p := range points {
+		builder.add(p.at, mustDecimal(t, p.bid).Decimal)
+	}
+
+	if len(builder.candles) != 2 {
+		t.Fatalf("esperado 2 candles, obtido %d", len(builder.candles))
+	}
+	first := builder.candles[0]
+	if first.Start != "2024-01-01T00:00:00Z" || first.Open.String() != "5" || first.High.String() != "5.2" ||
+		first.Low.String() != "4.9" || first.Close.String() != "5.1" || first.Count != 4 {
+		t.Errorf("primeiro candle incorreto: %+v", first)
+	}
+	// Intervalos sem cotações não geram candles
+	second := builder.candles[1]
+	if second.Start != "2024-01-01T02:00:00Z" || second.Open.String() != "5.3" || second.Close.String() != "5.3" || second.Count != 1 {
+		t.Errorf("segundo candle incorreto: %+v", second)
+	}
+}
+
+func TestStatsAccumulator(t *testing.T) {
+	var stats statsAccumulator
+	for _, bid := range []string{"2", "4", "4", "4", "5", "5", "7", "9"} {
+		stats.add(0, mustDecimal(t, bid).Decimal)
+	}
+	var response StatsResponse
+	if err := stats.result(&response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Count != 8 || response.Min.String() != "2" || response.Max.String() != "9" ||
+		response.Mean.String() != "5" || response.Stddev.String() != "2" {
+		t.Errorf("estatísticas incorretas: %+v", response)
+	}
+	if response.First.String() != "2" || response.Last.String() != "9" || response.Change != "350.0000" {
+		t.Errorf("variação incorreta: first=%s last=%s change=%s", response.First, response.Last, response.Change)
+	}
+}
+
+func TestStatsAccumulatorEmpty(t *testing.T) {
+	var stats statsAccumulator
+	var response StatsResponse
+	if err := stats.result(&response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Count != 0 || response.Mean.Valid || response.Change != "" {
+		t.Errorf("janela vazia deveria ter apenas count=0: %+v", response)
+	}
+}
@@ -0,0 +1,428 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/isaacmirandacampos/go-expert/quotation-api/internal/decimal"
+)
+
+const (
+	defaultDatabasePath = "./conversions.db"
+	defaultMaxOpenConns = 4
+	defaultMaxIdleConns = 4
+	defaultConnMaxIdle  = 5 * time.Minute
+)
+
+// Timeouts padrão de cada etapa
+var defaultStoreTimeouts = StoreTimeouts{
+	Begin:   10 * time.Millisecond,
+	Prepare: 10 * time.Millisecond,
+	Exec:    10 * time.Millisecond,
+	Commit:  10 * time.Millisecond,
+	Query:   200 * time.Millisecond,
+	Lookup:  10 * time.Millisecond,
+	Stats:   1 * time.Second,
+}
+
+// Conexão de longa duração com o SQLite, compartilhada por todo o servidor
+type Store struct {
+	db       *sql.DB
+	timeouts StoreTimeouts
+
+	listenersMu sync.RWMutex
+	listeners   []func(StoredQuote)
+}
+
+type StoreConfig struct {
+	Path            string
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxIdleTime time.Duration
+	Timeouts        StoreTimeouts
+}
+
+// Begin, Prepare, Exec e Commit limitam as etapas da transação de escrita das
+// cotações. Query vale para listagens e comandos avulsos, Lookup para a busca
+// da última cotação de um par e Stats para as agregações de candles e estatísticas.
+type StoreTimeouts struct {
+	Begin   time.Duration
+	Prepare time.Duration
+	Exec    time.Duration
+	Commit  time.Duration
+	Query   time.Duration
+	Lookup  time.Duration
+	Stats   time.Duration
+}
+
+// Lê QUOTE_DB_PATH, QUOTE_DB_MAX_OPEN_CONNS, QUOTE_DB_MAX_IDLE_CONNS,
+// QUOTE_DB_CONN_MAX_IDLE_TIME e os timeouts QUOTE_DB_<ETAPA>_TIMEOUT
+func storeConfigFromEnv() (StoreConfig, error) {
+	config := StoreConfig{
+		Path:            defaultDatabasePath,
+		MaxOpenConns:    defaultMaxOpenConns,
+		MaxIdleConns:    defaultMaxIdleConns,
+		ConnMaxIdleTime: defaultConnMaxIdle,
+		Timeouts:        defaultStoreTimeouts,
+	}
+	if value := os.Getenv("QUOTE_DB_PATH"); value != "" {
+		config.Path = value
+	}
+	var err error
+	if value := os.Getenv("QUOTE_DB_MAX_OPEN_CONNS"); value != "" {
+		config.MaxOpenConns, err = strconv.Atoi(value)
+		if err != nil || config.MaxOpenConns <= 0 {
+			return config, errors.New("invalid QUOTE_DB_MAX_OPEN_CONNS: " + value)
+		}
+	}
+	if value := os.Getenv("QUOTE_DB_MAX_IDLE_CONNS"); value != "" {
+		config.MaxIdleConns, err = strconv.Atoi(value)
+		if err != nil || config.MaxIdleConns < 0 {
+			return config, errors.New("invalid QUOTE_DB_MAX_IDLE_CONNS: " + value)
+		}
+	}
+	if value := os.Getenv("QUOTE_DB_CONN_MAX_IDLE_TIME"); value != "" {
+		config.ConnMaxIdleTime, err = time.ParseDuration(value)
+		if err != nil || config.ConnMaxIdleTime < 0 {
+			return config, errors.New("invalid QUOTE_DB_CONN_MAX_IDLE_TIME: " + value)
+		}
+	}
+	timeouts := []struct {
+		name  string
+		value *time.Duration
+	}{
+		{"QUOTE_DB_BEGIN_TIMEOUT", &config.Timeouts.Begin},
+		{"QUOTE_DB_PREPARE_TIMEOUT", &config.Timeouts.Prepare},
+		{"QUOTE_DB_EXEC_TIMEOUT", &config.Timeouts.Exec},
+		{"QUOTE_DB_COMMIT_TIMEOUT", &config.Timeouts.Commit},
+		{"QUOTE_DB_QUERY_TIMEOUT", &config.Timeouts.Query},
+		{"QUOTE_DB_LOOKUP_TIMEOUT", &config.Timeouts.Lookup},
+		{"QUOTE_DB_STATS_TIMEOUT", &config.Timeouts.Stats},
+	}
+	for _, t := range timeouts {
+		if value := os.Getenv(t.name); value != "" {
+			*t.value, err = time.ParseDuration(value)
+			if err != nil || *t.value <= 0 {
+				return config, errors.New("invalid " + t.name + ": " + value)
+			}
+		}
+	}
+	return config, nil
+}
+
+// Abre o banco em modo WAL, em que leituras não bloqueiam a escrita, e com
+// busy_timeout, para que escritas concorrentes esperem a vez em vez de
+// falharem com "database is locked".
+func NewStore(config StoreConfig) (*Store, error) {
+	dsn := "file:" + config.Path + "?_journal_mode=WAL&_busy_timeout=5000"
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, errors.New("error opening database")
+	}
+	db.SetMaxOpenConns(config.MaxOpenConns)
+	db.SetMaxIdleConns(config.MaxIdleConns)
+	db.SetConnMaxIdleTime(config.ConnMaxIdleTime)
+	if err = db.Ping(); err != nil {
+		db.Close()
+		return nil, errors.New("error connecting to database: " + err.Error())
+	}
+	return &Store{db: db, timeouts: config.Timeouts}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func (s *Store) Ping() error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Query)
+	defer cancel()
+	if err := s.db.PingContext(ctx); err != nil {
+		return databaseError(ctx, "ping", err)
+	}
+	return nil
+}
+
+func (s *Store) Migrate() (int, error) {
+	return migrateUp(s.db)
+}
+
+// Cotação a registrar num lote
+type QuoteRecord struct {
+	Pair  Pair
+	Quote *Quote
+}
+
+func (s *Store) SaveQuote(p Pair, q *Quote) error {
+	return s.SaveQuotes([]QuoteRecord{{Pair: p, Quote: q}})
+}
+
+// Registra as cotações numa única transação: ou todas são gravadas ou nenhuma.
+// Cada etapa tem o seu próprio timeout (ver StoreTimeouts); qualquer falha
+// desfaz a transação inteira, inclusive um timeout no commit.
+func (s *Store) SaveQuotes(records []QuoteRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	// O contexto da transação só é cancelado pelos timeouts de begin e commit,
+	// que não aceitam um contexto por etapa
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+
+	// Inicia a transação
+	stop := stageTimeout(cancel, s.timeouts.Begin)
+	tx, err := s.db.BeginTx(ctx, nil)
+	stop()
+	if err != nil {
+		// Verifica se o erro foi causado pelo timeout
+		if timedOut(ctx) {
+			log.Println("Transaction Timeout")
+		}
+		return databaseError(ctx, "begin", err)
+	}
+	// Sem efeito depois do commit
+	defer tx.Rollback()
+
+	// Prepara a consulta de inserção dentro da transação
+	prepareCtx, cancelPrepare := context.WithTimeout(ctx, s.timeouts.Prepare)
+	defer cancelPrepare()
+	stmt, err := tx.PrepareContext(prepareCtx, `INSERT INTO quotes (pair, bid, ask, high, low, source_timestamp, provider, inserted_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		// Verifica se o erro foi causado pelo timeout
+		if timedOut(prepareCtx) {
+			log.Println("Prepare Timeout")
+		}
+		return databaseError(prepareCtx, "prepare", err)
+	}
+	defer stmt.Close()
+
+	// Executa as inserções; o timeout vale para o lote inteiro
+	execCtx, cancelExec := context.WithTimeout(ctx, s.timeouts.Exec)
+	defer cancelExec()
+	insertedAt := time.Now().Unix()
+	stored := make([]StoredQuote, 0, len(records))
+	for _, r := range records {
+		p, q := r.Pair, r.Quote
+		result, err := stmt.ExecContext(execCtx, p.String(), q.Bid, q.Ask, q.High, q.Low, sourceTimestamp(q), q.Provider, insertedAt)
+		if err != nil {
+			// Verifica se o erro foi causado pelo timeout
+			if timedOut(execCtx) {
+				log.Println("Execute Timeout")
+			}
+			return databaseError(execCtx, "exec", err)
+		}
+		id, _ := result.LastInsertId()
+		stored = append(stored, StoredQuote{
+			ID:              id,
+			Pair:            p.String(),
+			Bid:             decimal.NullDecimal{Decimal: q.Bid, Valid: true},
+			Ask:             q.Ask,
+			High:            q.High,
+			Low:             q.Low,
+			SourceTimestamp: sourceTimestamp(q),
+			Provider:        sql.NullString{String: q.Provider, Valid: true},
+			InsertedAt:      sql.NullInt64{Int64: insertedAt, Valid: true},
+		})
+	}
+
+	// Commit da transação
+	stop = stageTimeout(cancel, s.timeouts.Commit)
+	err = tx.Commit()
+	stop()
+	if err != nil {
+		// Verifica se o erro foi causado pelo timeout
+		if timedOut(ctx) {
+			log.Println("Commit Timeout")
+		}
+		return databaseError(ctx, "commit", err)
+	}
+
+	// Nenhum erro ocorrido, a transação foi bem-sucedida
+	for _, q := range stored {
+		s.notifyQuoteStored(q)
+	}
+	return nil
+}
+
+// Cancela o contexto da transação se a etapa passar do timeout, o que a desfaz
+func stageTimeout(cancel context.CancelCauseFunc, timeout time.Duration) (stop func()) {
+	timer := time.AfterFunc(timeout, func() { cancel(context.DeadlineExceeded) })
+	return func() { timer.Stop() }
+}
+
+// Chamados a cada cotação registrada. Não devem bloquear: rodam no caminho de escrita.
+func (s *Store) OnQuoteStored(listener func(StoredQuote)) {
+	s.listenersMu.Lock()
+	defer s.listenersMu.Unlock()
+	s.listeners = append(s.listeners, listener)
+}
+
+func (s *Store) notifyQuoteStored(q StoredQuote) {
+	s.listenersMu.RLock()
+	defer s.listenersMu.RUnlock()
+	for _, listener := range s.listeners {
+		listener(q)
+	}
+}
+
+// Timestamp da cotação informado pelo provedor, em segundos
+func sourceTimestamp(q *Quote) sql.NullInt64 {
+	ts, err := strconv.ParseInt(q.Timestamp, 10, 64)
+	if err != nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: ts, Valid: true}
+}
+
+type StoredQuote struct {
+	ID              int64
+	Pair            string
+	Bid             decimal.NullDecimal
+	Ask             decimal.NullDecimal
+	High            decimal.NullDecimal
+	Low             decimal.NullDecimal
+	SourceTimestamp sql.NullInt64
+	Provider        sql.NullString
+	InsertedAt      sql.NullInt64
+}
+
+type HistoryFilter struct {
+	Pair string
+	// Alternativa a Pair para filtrar por vários pares
+	Pairs  []string
+	From   time.Time
+	To     time.Time
+	Cursor int64
+	Limit  int
+}
+
+// Monta a consulta do histórico; Limit 0 não limita a quantidade de linhas
+func historyQuery(f HistoryFilter) (string, []any) {
+	query := `SELECT rowid, pair, bid, ask, high, low, source_timestamp, provider, inserted_at FROM quotes WHERE rowid > ?`
+	args := []any{f.Cursor}
+	if f.Pair != "" {
+		query += ` AND pair = ?`
+		args = append(args, f.Pair)
+	}
+	if len(f.Pairs) > 0 {
+		query += ` AND pair IN (?` + strings.Repeat(`, ?`, len(f.Pairs)-1) + `)`
+		for _, pair := range f.Pairs {
+			args = append(args, pair)
+		}
+	}
+	if !f.From.IsZero() {
+		query += ` AND inserted_at >= ?`
+		args = append(args, f.From.Unix())
+	}
+	if !f.To.IsZero() {
+		query += ` AND inserted_at <= ?`
+		args = append(args, f.To.Unix())
+	}
+	query += ` ORDER BY rowid`
+	if f.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, f.Limit)
+	}
+	return query, args
+}
+
+func scanStoredQuote(row interface{ Scan(...any) error }) (StoredQuote, error) {
+	var q StoredQuote
+	err := row.Scan(&q.ID, &q.Pair, &q.Bid, &q.Ask, &q.High, &q.Low, &q.SourceTimestamp, &q.Provider, &q.InsertedAt)
+	return q, err
+}
+
+func (s *Store) ListQuotes(f HistoryFilter) ([]StoredQuote, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Query)
+	defer cancel()
+
+	query, args := historyQuery(f)
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			log.Println("Query Timeout")
+		}
+		return nil, databaseError(ctx, "query", err)
+	}
+	defer rows.Close()
+
+	quotes := []StoredQuote{}
+	for rows.Next() {
+		q, err := scanStoredQuote(rows)
+		if err != nil {
+			return nil, databaseError(ctx, "scan", err)
+		}
+		quotes = append(quotes, q)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, databaseError(ctx, "query", err)
+	}
+	return quotes, nil
+}
+
+// Última cotação registrada para o par, usada quando nenhum provedor responde
+func (s *Store) LatestQuote(p Pair) (*StoredQuote, error) {
+	return s.QuoteAt(p, time.Time{})
+}
+
+// Última cotação registrada para o par até o instante informado
+func (s *Store) QuoteAt(p Pair, at time.Time) (*StoredQuote, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Lookup)
+	defer cancel()
+
+	query := `SELECT rowid, pair, bid, ask, high, low, source_timestamp, provider, inserted_at FROM quotes WHERE pair = ?`
+	args := []any{p.String()}
+	if !at.IsZero() {
+		query += ` AND inserted_at <= ? ORDER BY inserted_at DESC, rowid DESC LIMIT 1`
+		args = append(args, at.Unix())
+	} else {
+		query += ` ORDER BY rowid DESC LIMIT 1`
+	}
+
+	var q StoredQuote
+	err := s.db.QueryRowContext(ctx, query, args...).
+		Scan(&q.ID, &q.Pair, &q.Bid, &q.Ask, &q.High, &q.Low, &q.SourceTimestamp, &q.Provider, &q.InsertedAt)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			log.Println("Query Timeout")
+		}
+		return nil, databaseError(ctx, "query", err)
+	}
+	return &q, nil
+}
+
+// Percorre as cotações do filtro sem carregá-las em memória. Não tem timeout
+// próprio: exportações longas são limitadas pelo contexto de quem chama.
+func (s *Store) EachQuote(ctx context.Context, f HistoryFilter, fn func(StoredQuote) error) error {
+	query, args := historyQuery(f)
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return databaseError(ctx, "query", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		q, err := scanStoredQuote(rows)
+		if err != nil {
+			return databaseError(ctx, "scan", err)
+		}
+		if err = fn(q); err != nil {
+			return err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return databaseError(ctx, "query", err)
+	}
+	return nil
+}
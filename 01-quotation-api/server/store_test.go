@@ -0,0 +1,129 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/isaacmirandacampos/go-expert/quotation-api/internal/decimal"
+)
+
+// Banco em memória com uma única conexão: cada conexão teria o seu próprio banco
+func newTestStore(t *testing.T, timeouts StoreTimeouts) *Store {
+	t.Helper()
+	store, err := NewStore(StoreConfig{Path: ":memory:", MaxOpenConns: 1, MaxIdleConns: 1, Timeouts: timeouts})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+	if _, err = store.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func testQuote(t *testing.T, bid string) *Quote {
+	t.Helper()
+	d, err := decimal.Parse(bid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Quote{Bid: d, Provider: "test"}
+}
+
+func countQuotes(t *testing.T, store *Store) int {
+	t.Helper()
+	var count int
+	if err := store.db.QueryRow(`SELECT COUNT(*) FROM quotes`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func TestSaveQuotesBatch(t *testing.T) {
+	store := newTestStore(t, defaultStoreTimeouts)
+	var notified []StoredQuote
+	store.OnQuoteStored(func(q StoredQuote) { notified = append(notified, q) })
+
+	err := store.SaveQuotes([]QuoteRecord{
+		{Pair: Pair{From: "USD", To: "BRL"}, Quote: testQuote(t, "5.1234")},
+		{Pair: Pair{From: "EUR", To: "BRL"}, Quote: testQuote(t, "6.01")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if countQuotes(t, store) != 2 {
+		t.Fatalf("esperado 2 cotações registradas")
+	}
+	if len(notified) != 2 || notified[0].ID == notified[1].ID || notified[1].Pair != "EUR-BRL" {
+		t.Errorf("notificações incorretas: %+v", notified)
+	}
+	q, err := store.LatestQuote(Pair{From: "EUR", To: "BRL"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.ID != notified[1].ID || q.Bid.Decimal.String() != "6.01" {
+		t.Errorf("cotação registrada incorreta: %+v", q)
+	}
+}
+
+// Uma falha no meio do lote desfaz as inserções anteriores: nada fica gravado
+// fora da transação
+func TestSaveQuotesRollsBackBatch(t *testing.T) {
+	store := newTestStore(t, defaultStoreTimeouts)
+	_, err := store.db.Exec(`CREATE TRIGGER reject_eur BEFORE INSERT ON quotes WHEN NEW.pair = 'EUR-BRL' BEGIN SELECT RAISE(ABORT, 'rejected'); END`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notified := 0
+	store.OnQuoteStored(func(StoredQuote) { notified++ })
+
+	err = store.SaveQuotes([]QuoteRecord{
+		{Pair: Pair{From: "USD", To: "BRL"}, Quote: testQuote(t, "5.1234")},
+		{Pair: Pair{From: "EUR", To: "BRL"}, Quote: testQuote(t, "6.01")},
+	})
+	var database *DatabaseError
+	if !errors.As(err, &database) || database.Stage != "exec" || database.Timeout {
+		t.Fatalf("esperada falha na etapa exec: %v", err)
+	}
+	if count := countQuotes(t, store); count != 0 {
+		t.Errorf("%d cotações ficaram gravadas depois do rollback", count)
+	}
+	if notified != 0 {
+		t.Errorf("listeners notificados sem commit")
+	}
+}
+
+func TestSaveQuoteExecTimeout(t *testing.T) {
+	timeouts := defaultStoreTimeouts
+	timeouts.Exec = -time.Second
+	store := newTestStore(t, timeouts)
+
+	err := store.SaveQuote(Pair{From: "USD", To: "BRL"}, testQuote(t, "5.1234"))
+	if !errors.Is(err, ErrDatabaseTimeout) {
+		t.Fatalf("esperado timeout do banco: %v", err)
+	}
+	if count := countQuotes(t, store); count != 0 {
+		t.Errorf("%d cotações ficaram gravadas depois do timeout", count)
+	}
+}
+
+func TestStoreConfigFromEnvTimeouts(t *testing.T) {
+	t.Setenv("QUOTE_DB_COMMIT_TIMEOUT", "50ms")
+	t.Setenv("QUOTE_DB_QUERY_TIMEOUT", "1s")
+	config, err := storeConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Timeouts.Commit != 50*time.Millisecond || config.Timeouts.Query != time.Second {
+		t.Errorf("timeouts incorretos: %+v", config.Timeouts)
+	}
+	if config.Timeouts.Begin != defaultStoreTimeouts.Begin {
+		t.Errorf("timeout sem variável deveria manter o padrão: %s", config.Timeouts.Begin)
+	}
+
+	t.Setenv("QUOTE_DB_EXEC_TIMEOUT", "0s")
+	if _, err = storeConfigFromEnv(); err == nil {
+		t.Errorf("timeout zero deveria ser rejeitado")
+	}
+}
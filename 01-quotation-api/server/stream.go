@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	streamBufferSize   = 64
+	streamHeartbeat    = 15 * time.Second
+	streamWriteTimeout = 10 * time.Second
+	streamRetry        = 3 * time.Second
+)
+
+type subscriber struct {
+	// Pares acompanhados; vazio acompanha todos
+	pairs  map[string]bool
+	quotes chan StoredQuote
+}
+
+// Distribui as cotações registradas para os streams abertos. A publicação
+// nunca bloqueia a escrita no banco: quem não consome rápido o bastante
+// para esvaziar o buffer é desconectado e retoma pelo Last-Event-ID.
+type QuoteBroker struct {
+	mu          sync.Mutex
+	subscribers map[*subscriber]struct{}
+	closed      bool
+}
+
+func NewQuoteBroker() *QuoteBroker {
+	return &QuoteBroker{subscribers: map[*subscriber]struct{}{}}
+}
+
+// Retorna nil se o broker já foi fechado
+func (b *QuoteBroker) Subscribe(pairs []string) *subscriber {
+	sub := &subscriber{pairs: map[string]bool{}, quotes: make(chan StoredQuote, streamBufferSize)}
+	for _, pair := range pairs {
+		sub.pairs[pair] = true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil
+	}
+	b.subscribers[sub] = struct{}{}
+	return sub
+}
+
+func (b *QuoteBroker) Unsubscribe(sub *subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subscribers[sub]; ok {
+		delete(b.subscribers, sub)
+		close(sub.quotes)
+	}
+}
+
+func (b *QuoteBroker) Publish(q StoredQuote) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for sub := range b.subscribers {
+		if len(sub.pairs) > 0 && !sub.pairs[q.Pair] {
+			continue
+		}
+		select {
+		case sub.quotes <- q:
+		default:
+			log.Printf("Stream subscriber too slow, disconnecting at quote %d\n", q.ID)
+			delete(b.subscribers, sub)
+			close(sub.quotes)
+		}
+	}
+}
+
+// Encerra todos os streams; usado no desligamento do servidor
+func (b *QuoteBroker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for sub := range b.subscribers {
+		delete(b.subscribers, sub)
+		close(sub.quotes)
+	}
+}
+
+func parseStreamPairs(value string) ([]string, error) {
+	pairs := []string{}
+	if value == "" {
+		return pairs, nil
+	}
+	for _, item := range strings.Split(value, ",") {
+		p, err := parsePair(strings.TrimSpace(item))
+		if err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, p.String())
+	}
+	return pairs, nil
+}
+
+// Envia um evento a cada cotação registrada dos pares pedidos. Com o header
+// Last-Event-ID (enviado pelo EventSource ao reconectar), as cotações
+// registradas depois daquele id são reenviadas a partir do histórico antes
+// das novas.
+func (s *Server) streamHandler(w http.ResponseWriter, r *http.Request) {
+	pairs, err := parseStreamPairs(r.URL.Query().Get("pairs"))
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	var lastID int64
+	value := r.Header.Get("Last-Event-ID")
+	resume := value != ""
+	if resume {
+		lastID, err = strconv.ParseInt(value, 10, 64)
+		if err != nil || lastID < 0 {
+			writeProblem(w, r, http.StatusBadRequest, "invalid Last-Event-ID")
+			return
+		}
+	}
+
+	// Inscreve antes de ler o histórico para não perder cotações entre os dois
+	sub := s.broker.Subscribe(pairs)
+	if sub == nil {
+		writeProblem(w, r, http.StatusServiceUnavailable, "server shutting down")
+		return
+	}
+	defer s.broker.Unsubscribe(sub)
+
+	rc := http.NewResponseController(w)
+	send := func(payload string) error {
+		// O WriteTimeout do servidor não serve para conexões longas; cada escrita tem o seu prazo
+		rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+		if _, err := fmt.Fprint(w, payload); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+	sendQuote := func(q StoredQuote) error {
+		data, err := json.Marshal(newHistoryItem(q))
+		if err != nil {
+			return err
+		}
+		return send(fmt.Sprintf("id: %d\nevent: quote\ndata: %s\n\n", q.ID, data))
+	}
+	heartbeat := func() error {
+		return send(": heartbeat\n\n")
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err = send(fmt.Sprintf("retry: %d\n\n", streamRetry.Milliseconds())); err != nil {
+		return
+	}
+	// Falhas de escrita só indicam que o cliente desconectou
+	err = s.relayQuotes(r.Context(), sub, pairs, resume, lastID, sendQuote, heartbeat)
+	if errors.Is(err, ErrDatabase) {
+		log.Println("Error replaying stream:", err)
+	}
+}
+
+// Envia as cotações registradas depois de afterID a partir do histórico
+// (quando resume) e em seguida as publicadas no broker, até o contexto ser
+// cancelado ou o broker encerrar a inscrição. heartbeat, se não for nil, é
+// chamado a cada streamHeartbeat sem cotações. Compartilhado pelo SSE e pelo gRPC.
+func (s *Server) relayQuotes(ctx context.Context, sub *subscriber, pairs []string, resume bool, afterID int64, send func(StoredQuote) error, heartbeat func() error) error {
+	lastID := afterID
+	if resume {
+		for {
+			quotes, err := s.store.ListQuotes(HistoryFilter{Pairs: pairs, Cursor: lastID, Limit: maxHistoryLimit})
+			if err != nil {
+				return err
+			}
+			for _, q := range quotes {
+				if err = send(q); err != nil {
+					return err
+				}
+				lastID = q.ID
+			}
+			if len(quotes) < maxHistoryLimit {
+				break
+			}
+		}
+	}
+
+	var beat <-chan time.Time
+	if heartbeat != nil {
+		ticker := time.NewTicker(streamHeartbeat)
+		defer ticker.Stop()
+		beat = ticker.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case q, ok := <-sub.quotes:
+			if !ok {
+				return nil
+			}
+			// Já enviada pelo histórico
+			if q.ID <= lastID {
+				continue
+			}
+			if err := send(q); err != nil {
+				return err
+			}
+			lastID = q.ID
+		case <-beat:
+			if err := heartbeat(); err != nil {
+				return err
+			}
+		}
+	}
+}
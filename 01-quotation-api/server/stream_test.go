@@ -0,0 +1,59 @@
+package main
+
+import "testing"
+
+func TestQuoteBrokerFiltersPairs(t *testing.T) {
+	broker := NewQuoteBroker()
+	sub := broker.Subscribe([]string{"EUR-BRL"})
+	broker.Publish(StoredQuote{ID: 1, Pair: "USD-BRL"})
+	broker.Publish(StoredQuote{ID: 2, Pair: "EUR-BRL"})
+
+	select {
+	case q := <-sub.quotes:
+		if q.ID != 2 {
+			t.Errorf("esperada a cotação 2, recebida %d", q.ID)
+		}
+	default:
+		t.Fatal("nenhuma cotação recebida")
+	}
+	if len(sub.quotes) != 0 {
+		t.Errorf("cotação de outro par entregue")
+	}
+}
+
+func TestQuoteBrokerDropsSlowSubscriber(t *testing.T) {
+	broker := NewQuoteBroker()
+	slow := broker.Subscribe(nil)
+	fast := broker.Subscribe(nil)
+
+	// Publish não pode bloquear mesmo com um inscrito que não consome
+	for i := int64(1); i <= streamBufferSize+1; i++ {
+		broker.Publish(StoredQuote{ID: i, Pair: "USD-BRL"})
+		<-fast.quotes
+	}
+
+	received := 0
+	for range slow.quotes {
+		received++
+	}
+	if received != streamBufferSize {
+		t.Errorf("esperadas %d cotações antes da desconexão, recebidas %d", streamBufferSize, received)
+	}
+	if _, ok := broker.subscribers[fast]; !ok {
+		t.Errorf("inscrito que acompanha o ritmo não deveria ser desconectado")
+	}
+	// Unsubscribe depois da desconexão não fecha o canal de novo
+	broker.Unsubscribe(slow)
+}
+
+func TestQuoteBrokerClose(t *testing.T) {
+	broker := NewQuoteBroker()
+	sub := broker.Subscribe(nil)
+	broker.Close()
+	if _, ok := <-sub.quotes; ok {
+		t.Errorf("canal deveria estar fechado")
+	}
+	if broker.Subscribe(nil) != nil {
+		t.Errorf("inscrição depois do Close deveria falhar")
+	}
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/isaacmirandacampos/go-expert/02-api-concurrency-using-multithreading/cep"
+)
+
+type batchConfig struct {
+	input   string
+	column  string
+	format  string
+	output  string
+	workers int
+}
+
+// Consulta cada CEP da entrada e escreve os resultados na ordem da entrada;
+// no fim, imprime um resumo das falhas no stderr
+func runBatch(ctx context.Context, client *cep.Client, config batchConfig) error {
+	in := os.Stdin
+	if config.input != "-" {
+		f, err := os.Open(config.input)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	out := os.Stdout
+	if config.output != "" && config.output != "-" {
+		f, err := os.Create(config.output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	buffered := bufio.NewWriter(out)
+	w, err := newResultWriter(config.format, buffered)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	ceps := make(chan string)
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(ceps)
+		readErr <- readCEPs(ctx, in, config.column, ceps)
+	}()
+
+	var summary batchSummary
+	start := time.Now()
+	err = client.LookupBatch(ctx, ceps, config.workers, func(r cep.BatchResult) error {
+		summary.add(r.Err)
+		return w.Write(r)
+	})
+	// Para a leitura se as consultas pararam antes
+	cancel()
+	if flushErr := w.Flush(); err == nil {
+		err = flushErr
+	}
+	if flushErr := buffered.Flush(); err == nil {
+		err = flushErr
+	}
+	if readErr := <-readErr; err == nil {
+		err = readErr
+	}
+	fmt.Fprintln(os.Stderr, summary.String(time.Since(start)))
+	return err
+}
+
+// Envia cada linha de r, ou cada valor de column quando r é um CSV cuja
+// primeira linha nomeia as colunas
+func readCEPs(ctx context.Context, r io.Reader, column string, ceps chan<- string) error {
+	// Valores em branco também são enviados: as linhas recebem ErrInvalidCep,
+	// mantendo a saída alinhada com a entrada
+	send := func(value string) bool {
+		select {
+		case ceps <- strings.TrimSpace(value):
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	if column == "" {
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			if !send(scanner.Text()) {
+				return nil
+			}
+		}
+		return scanner.Err()
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("error reading CSV header: %w", err)
+	}
+	index := -1
+	for i, name := range header {
+		if strings.EqualFold(strings.TrimSpace(name), column) {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return errors.New("CSV has no column " + column)
+	}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error reading CSV: %w", err)
+		}
+		value := ""
+		if index < len(record) {
+			value = record[index]
+		}
+		if !send(value) {
+			return nil
+		}
+	}
+}
+
+type resultWriter interface {
+	Write(r cep.BatchResult) error
+	Flush() error
+}
+
+func newResultWriter(format string, w io.Writer) (resultWriter, error) {
+	switch format {
+	case "csv":
+		return newCSVResultWriter(w)
+	case "jsonl":
+		return &jsonlResultWriter{encoder: json.NewEncoder(w)}, nil
+	}
+	return nil, errors.New("invalid format: " + format)
+}
+
+type csvResultWriter struct {
+	w *csv.Writer
+}
+
+func newCSVResultWriter(w io.Writer) (*csvResultWriter, error) {
+	c := &csvResultWriter{w: csv.NewWriter(w)}
+	err := c.w.Write([]string{"input", "api_used", "cep", "estado", "cidade", "bairro", "rua", "error"})
+	return c, err
+}
+
+func (c *csvResultWriter) Write(r cep.BatchResult) error {
+	errMessage := ""
+	if r.Err != nil {
+		errMessage = r.Err.Error()
+	}
+	res := r.Result
+	return c.w.Write([]string{r.Input, res.ApiUsed, res.Cep, res.Estado, res.Cidade, res.Bairro, res.Rua, errMessage})
+}
+
+func (c *csvResultWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type jsonlResultWriter struct {
+	encoder *json.Encoder
+}
+
+type jsonlResult struct {
+	Input  string         `json:"input"`
+	Result *cep.CepResult `json:"result,omitempty"`
+	Error  string         `json:"error,omitempty"`
+}
+
+func (j *jsonlResultWriter) Write(r cep.BatchResult) error {
+	line := jsonlResult{Input: r.Input}
+	if r.Err != nil {
+		line.Error = r.Err.Error()
+	} else {
+		line.Result = &r.Result
+	}
+	return j.encoder.Encode(line)
+}
+
+func (j *jsonlResultWriter) Flush() error {
+	return nil
+}
+
+type batchSummary struct {
+	found, notFound, invalid, timedOut, failed int
+}
+
+func (s *batchSummary) add(err error) {
+	switch {
+	case err == nil:
+		s.found++
+	case errors.Is(err, cep.ErrInvalidCep):
+		s.invalid++
+	case errors.Is(err, cep.ErrNotFound):
+		s.notFound++
+	case errors.Is(err, context.DeadlineExceeded):
+		s.timedOut++
+	default:
+		s.failed++
+	}
+}
+
+func (s *batchSummary) String(elapsed time.Duration) string {
+	total := s.found + s.notFound + s.invalid + s.timedOut + s.failed
+	return fmt.Sprintf("Looked up %d CEPs in %v: %d found, %d not found, %d invalid, %d timed out, %d failed",
+		total, elapsed.Round(time.Millisecond), s.found, s.notFound, s.invalid, s.timedOut, s.failed)
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/isaacmirandacampos/go-expert/02-api-concurrency-using-multithreading/cep"
+)
+
+func TestReadCEPs(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		column string
+		want   []string
+	}{
+		{"um por linha", "01153000\n\n  01310-100 \r\n", "", []string{"01153000", "", "01310-100"}},
+		{"coluna do CSV", "id,Cep,city\n1,01153000,SP\n2,,SP\n3,\"01310-100\",SP\n", "cep", []string{"01153000", "", "01310-100"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ceps := make(chan string)
+			errc := make(chan error, 1)
+			go func() {
+				defer close(ceps)
+				errc <- readCEPs(context.Background(), strings.NewReader(tt.input), tt.column, ceps)
+			}()
+			var got []string
+			for cep := range ceps {
+				got = append(got, cep)
+			}
+			if err := <-errc; err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("recebido %q, esperado %q", got, tt.want)
+			}
+		})
+	}
+
+	ceps := make(chan string, 1)
+	if err := readCEPs(context.Background(), strings.NewReader("id,zip\n"), "cep", ceps); err == nil {
+		t.Error("esperado erro para uma coluna inexistente")
+	}
+}
+
+type echoProvider struct{}
+
+func (echoProvider) Name() string {
+	return "echo"
+}
+
+func (echoProvider) Fetch(ctx context.Context, code string) (*cep.CepResult, error) {
+	return &cep.CepResult{ApiUsed: "echo", Cep: code, Estado: "SP", Cidade: "São Paulo"}, nil
+}
+
+func TestRunBatchKeepsBlankLines(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "ceps.txt")
+	output := filepath.Join(dir, "results.csv")
+	if err := os.WriteFile(input, []byte("01153000\n\n01310100\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	client, err := cep.New(cep.Config{Providers: []cep.Provider{echoProvider{}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := batchConfig{input: input, format: "csv", output: output, workers: 2}
+	if err = runBatch(context.Background(), client, config); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 4 {
+		t.Fatalf("%d linhas, esperado o cabeçalho e uma por linha da entrada: %q", len(rows), rows)
+	}
+	if rows[1][2] != "01153000" || rows[3][2] != "01310100" {
+		t.Errorf("resultados fora de ordem: %q", rows)
+	}
+	if rows[2][0] != "" || rows[2][7] != cep.ErrInvalidCep.Error() {
+		t.Errorf("linha em branco deveria ser um CEP inválido: %q", rows[2])
+	}
+
+	var summary batchSummary
+	_, err = client.Lookup(context.Background(), "")
+	summary.add(err)
+	if summary.invalid != 1 {
+		t.Errorf("CEP em branco contado como %+v, esperado inválido", summary)
+	}
+}
@@ -0,0 +1,95 @@
+package cep
+
+import (
+	"context"
+	"sync"
+)
+
+// Consultas simultâneas do LookupBatch quando workers não é positivo
+const DefaultWorkers = 8
+
+// Resultado do CEP na posição Index do lote
+type BatchResult struct {
+	Index  int
+	Input  string
+	Result CepResult
+	Err    error
+}
+
+type batchJob struct {
+	BatchResult
+	done chan struct{}
+}
+
+// Consulta cada CEP recebido de ceps, com até workers consultas ao mesmo
+// tempo, e chama emit com cada resultado na ordem da entrada. Resultados
+// prontos antes de um mais lento esperam por ele, até uma janela de alguns
+// resultados por worker, então a memória fica limitada qualquer que seja o
+// tamanho da entrada. Retorna quando ceps é fechado e todos os resultados
+// foram emitidos, ou com o primeiro erro de emit ou do ctx; depois de um
+// erro, ceps não é esvaziado.
+func (c *Client) LookupBatch(ctx context.Context, ceps <-chan string, workers int, emit func(BatchResult) error) error {
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	// Nessa ordem para que o cancel rode primeiro: o distribuidor então fecha
+	// jobs e os workers podem terminar
+	defer wg.Wait()
+	defer cancel()
+
+	jobs := make(chan *batchJob)
+	// Na ordem da entrada; a capacidade é a janela de resultados em andamento
+	pending := make(chan *batchJob, 4*workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				job.Result, job.Err = c.Lookup(ctx, job.Input)
+				close(job.done)
+			}
+		}()
+	}
+	go func() {
+		defer close(pending)
+		defer close(jobs)
+		for index := 0; ; index++ {
+			var input string
+			var ok bool
+			select {
+			case input, ok = <-ceps:
+			case <-ctx.Done():
+				return
+			}
+			if !ok {
+				return
+			}
+			job := &batchJob{BatchResult: BatchResult{Index: index, Input: input}, done: make(chan struct{})}
+			select {
+			case pending <- job:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case jobs <- job:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for job := range pending {
+		select {
+		case <-job.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if err := emit(job.BatchResult); err != nil {
+			return err
+		}
+	}
+	// pending também é fechado quando o ctx termina antes da entrada
+	return ctx.Err()
+}
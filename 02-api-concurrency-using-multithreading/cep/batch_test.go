@@ -0,0 +1,134 @@
+package cep
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Responde depois de um atraso que varia com o CEP, contando as chamadas em andamento
+type concurrencyProvider struct {
+	inFlight    atomic.Int32
+	maxInFlight atomic.Int32
+}
+
+func (p *concurrencyProvider) Name() string {
+	return "concurrency"
+}
+
+func (p *concurrencyProvider) Fetch(ctx context.Context, cep string) (*CepResult, error) {
+	n := p.inFlight.Add(1)
+	defer p.inFlight.Add(-1)
+	for {
+		max := p.maxInFlight.Load()
+		if n <= max || p.maxInFlight.CompareAndSwap(max, n) {
+			break
+		}
+	}
+	select {
+	case <-time.After(time.Duration(cep[7]-'0') * time.Millisecond):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return &CepResult{ApiUsed: p.Name(), Cep: cep, Estado: "SP", Cidade: "São Paulo"}, nil
+}
+
+func sendAll(ceps []string) <-chan string {
+	ch := make(chan string)
+	go func() {
+		defer close(ch)
+		for _, cep := range ceps {
+			ch <- cep
+		}
+	}()
+	return ch
+}
+
+func TestLookupBatchPreservesOrder(t *testing.T) {
+	provider := &concurrencyProvider{}
+	client := newClient(t, Config{Providers: []Provider{provider}})
+	var ceps []string
+	for i := 0; i < 200; i++ {
+		ceps = append(ceps, fmt.Sprintf("0115%04d", (i*7919)%10000))
+	}
+	ceps[10] = "invalid"
+
+	var got []BatchResult
+	err := client.LookupBatch(context.Background(), sendAll(ceps), 8, func(r BatchResult) error {
+		got = append(got, r)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(ceps) {
+		t.Fatalf("%d resultados, esperado %d", len(got), len(ceps))
+	}
+	for i, r := range got {
+		if r.Index != i || r.Input != ceps[i] {
+			t.Fatalf("resultado %d é da entrada %d (%s)", i, r.Index, r.Input)
+		}
+		if i == 10 {
+			if !errors.Is(r.Err, ErrInvalidCep) {
+				t.Errorf("recebido %v para um CEP inválido, esperado ErrInvalidCep", r.Err)
+			}
+			continue
+		}
+		if r.Err != nil || r.Result.Cep != ceps[i] {
+			t.Errorf("resultado %d: %+v, %v", i, r.Result, r.Err)
+		}
+	}
+	if max := provider.maxInFlight.Load(); max > 8 {
+		t.Errorf("%d consultas simultâneas com 8 workers", max)
+	}
+}
+
+func TestLookupBatchProviderConcurrency(t *testing.T) {
+	provider := &concurrencyProvider{}
+	client := newClient(t, Config{Providers: []Provider{provider}, ProviderConcurrency: 2})
+	ceps := make([]string, 50)
+	for i := range ceps {
+		ceps[i] = "01153005"
+	}
+	err := client.LookupBatch(context.Background(), sendAll(ceps), 10, func(r BatchResult) error {
+		return r.Err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if max := provider.maxInFlight.Load(); max > 2 {
+		t.Errorf("%d chamadas simultâneas com limite de 2", max)
+	}
+}
+
+func TestLookupBatchStopsOnEmitError(t *testing.T) {
+	client := newClient(t, Config{Providers: []Provider{&concurrencyProvider{}}})
+	ceps := make(chan string)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		// Nunca é fechado: LookupBatch precisa retornar mesmo assim
+		for {
+			select {
+			case ceps <- "01153000":
+			case <-done:
+				return
+			}
+		}
+	}()
+	stop := errors.New("disk full")
+	emitted := 0
+	err := client.LookupBatch(context.Background(), ceps, 4, func(r BatchResult) error {
+		emitted++
+		if emitted == 5 {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) || emitted != 5 {
+		t.Errorf("recebido %v depois de %d resultados, esperado o erro do emit depois de 5", err, emitted)
+	}
+}
@@ -0,0 +1,53 @@
+package cep
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Formato da URL, que recebe o CEP
+const BrasilApiURL = "https://brasilapi.com.br/api/cep/v1/%s"
+
+type brasilApiResponse struct {
+	Cep          string `json:"cep"`
+	State        string `json:"state"`
+	City         string `json:"city"`
+	Neighborhood string `json:"neighborhood"`
+	Street       string `json:"street"`
+	Service      string `json:"service"`
+}
+
+type BrasilApi struct {
+	url    string
+	client *http.Client
+}
+
+func NewBrasilApi(url string, client *http.Client) *BrasilApi {
+	return &BrasilApi{url: url, client: client}
+}
+
+func (b *BrasilApi) Name() string {
+	return "BrasilApi"
+}
+
+func (b *BrasilApi) Fetch(ctx context.Context, cep string) (*CepResult, error) {
+	// CEPs desconhecidos são respondidos com 404 e um corpo de erro
+	res, err := get(ctx, b.client, fmt.Sprintf(b.url, cep), http.StatusNotFound)
+	if err != nil {
+		return nil, err
+	}
+	var brasilApi brasilApiResponse
+	if err := json.Unmarshal(res, &brasilApi); err != nil {
+		return nil, fmt.Errorf("error unmarshaling response: %w", err)
+	}
+	return &CepResult{
+		ApiUsed: b.Name(),
+		Cep:     brasilApi.Cep,
+		Estado:  brasilApi.State,
+		Cidade:  brasilApi.City,
+		Bairro:  brasilApi.Neighborhood,
+		Rua:     brasilApi.Street,
+	}, nil
+}
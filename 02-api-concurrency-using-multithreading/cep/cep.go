@@ -0,0 +1,288 @@
+// Package cep consulta CEPs em vários provedores e fica com a primeira
+// resposta bem-sucedida. Por padrão todos os provedores são chamados ao mesmo
+// tempo; as alternativas estão em Strategy.
+package cep
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Prazo de uma consulta quando Config.Timeout não é informado
+const DefaultTimeout = 1 * time.Second
+
+// Endereço do CEP no mesmo formato para todos os provedores
+type CepResult struct {
+	ApiUsed string `json:"api_used"`
+	Cep     string `json:"cep"`
+	Estado  string `json:"estado"`
+	Cidade  string `json:"cidade"`
+	Bairro  string `json:"bairro"`
+	Rua     string `json:"rua"`
+}
+
+// Busca o endereço de um CEP em uma única API
+type Provider interface {
+	Name() string
+	Fetch(ctx context.Context, cep string) (*CepResult, error)
+}
+
+type Config struct {
+	// Provedores consultados em cada busca; padrão ViaCep e BrasilApi
+	Providers []Provider
+	// Prazo da consulta inteira, além do prazo do contexto de quem chama;
+	// padrão DefaultTimeout
+	Timeout time.Duration
+	// Quando cada provedor é chamado; padrão StrategyRace
+	Strategy Strategy
+	// Quanto StrategyHedged espera por um provedor antes de chamar o
+	// próximo; padrão DefaultHedgeDelay
+	HedgeDelay time.Duration
+	// Se informado (ex: 0.9), StrategyHedged espera por esse percentil das
+	// latências recentes do provedor, depois de chamadas suficientes
+	// observadas. Maior que 0 e no máximo 1.
+	HedgePercentile float64
+	// Máximo de chamadas simultâneas a cada provedor, somando as consultas
+	// em andamento; acima do limite a chamada espera uma vaga dentro do
+	// prazo da consulta. Zero não limita.
+	ProviderConcurrency int
+}
+
+// Consulta CEPs nos seus provedores e guarda a latência e a taxa de sucesso
+// observadas de cada um. Pode ser usado por várias goroutines.
+type Client struct {
+	providers []Provider
+	stats     []*providerStats
+	// Semáforos de Config.ProviderConcurrency, nil sem limite
+	slots           []chan struct{}
+	timeout         time.Duration
+	strategy        Strategy
+	hedgeAfter      time.Duration
+	hedgePercentile float64
+}
+
+// Devolve erro para um Config.Strategy desconhecido ou um
+// Config.HedgePercentile fora de (0, 1]
+func New(config Config) (*Client, error) {
+	if config.Strategy != "" && !slices.Contains(Strategies, config.Strategy) {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownStrategy, config.Strategy)
+	}
+	if !(config.HedgePercentile >= 0 && config.HedgePercentile <= 1) {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidHedgePercentile, config.HedgePercentile)
+	}
+	providers := config.Providers
+	if providers == nil {
+		providers = DefaultProviders()
+	}
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	c := &Client{
+		providers:       providers,
+		stats:           make([]*providerStats, len(providers)),
+		slots:           make([]chan struct{}, len(providers)),
+		timeout:         timeout,
+		strategy:        config.Strategy,
+		hedgeAfter:      config.HedgeDelay,
+		hedgePercentile: config.HedgePercentile,
+	}
+	for i := range c.stats {
+		c.stats[i] = newProviderStats()
+		if config.ProviderConcurrency > 0 {
+			c.slots[i] = make(chan struct{}, config.ProviderConcurrency)
+		}
+	}
+	if c.strategy == "" {
+		c.strategy = StrategyRace
+	}
+	if c.hedgeAfter <= 0 {
+		c.hedgeAfter = DefaultHedgeDelay
+	}
+	return c, nil
+}
+
+// ViaCep e BrasilApi usando o http.DefaultClient
+func DefaultProviders() []Provider {
+	return []Provider{
+		NewViaCep(ViaCepURL, http.DefaultClient),
+		NewBrasilApi(BrasilApiURL, http.DefaultClient),
+	}
+}
+
+var defaultClient, _ = New(Config{})
+
+// Consulta os provedores padrão ao mesmo tempo, dentro de DefaultTimeout
+func Lookup(ctx context.Context, cep string) (CepResult, error) {
+	return defaultClient.Lookup(ctx, cep)
+}
+
+// Devolve a primeira resposta válida e completa dos provedores, chamados
+// conforme a estratégia do cliente. O CEP pode vir formatado ("01153-000").
+// Sem sucesso, o erro é um *LookupError com a falha de cada provedor, que só
+// corresponde a ErrNotFound se todos disseram que o CEP não existe. As
+// chamadas compartilham um contexto, cancelado quando Lookup retorna, então
+// as requisições que perderam a corrida são abortadas em vez de seguirem
+// até o prazo.
+func (c *Client) Lookup(ctx context.Context, cep string) (CepResult, error) {
+	if len(c.providers) == 0 {
+		return CepResult{}, ErrNoProviders
+	}
+	cep, err := normalize(cep)
+	if err != nil {
+		return CepResult{}, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	order := c.order()
+	// Com buffer, para que respostas atrasadas não travem a goroutine
+	outcomes := make(chan outcome, len(c.providers))
+	launched := 0
+	var hedge *time.Timer
+	var hedgeC <-chan time.Time
+	defer func() {
+		if hedge != nil {
+			hedge.Stop()
+		}
+	}()
+	launchNext := func() {
+		i := order[launched]
+		launched++
+		go func() {
+			if slot := c.slots[i]; slot != nil {
+				select {
+				case slot <- struct{}{}:
+					defer func() { <-slot }()
+				case <-ctx.Done():
+					outcomes <- outcome{index: i, err: ctx.Err()}
+					return
+				}
+			}
+			start := time.Now()
+			result, err := c.providers[i].Fetch(ctx, cep)
+			if err == nil {
+				err = result.validate(cep)
+			}
+			if !lostRace(ctx, err) {
+				c.stats[i].observe(time.Since(start), err)
+			}
+			outcomes <- outcome{index: i, result: result, err: err}
+		}()
+		if hedge != nil {
+			hedge.Stop()
+		}
+		hedgeC = nil
+		if c.strategy == StrategyHedged && launched < len(order) {
+			hedge = time.NewTimer(c.hedgeDelay(i))
+			hedgeC = hedge.C
+		}
+	}
+	launchNext()
+	for c.strategy == StrategyRace && launched < len(order) {
+		launchNext()
+	}
+
+	errs := make([]error, len(c.providers))
+	for answered := 0; answered < launched; {
+		select {
+		case o := <-outcomes:
+			answered++
+			if o.err == nil {
+				return *o.result, nil
+			}
+			errs[o.index] = o.err
+			if launched < len(order) {
+				launchNext()
+			}
+		case <-hedgeC:
+			launchNext()
+		case <-ctx.Done():
+			// Provedores que não responderam, ou não foram chamados, a tempo
+			for i := range errs {
+				if errs[i] == nil {
+					errs[i] = ctx.Err()
+				}
+			}
+			return CepResult{}, c.lookupError(cep, errs)
+		}
+	}
+	return CepResult{}, c.lookupError(cep, errs)
+}
+
+type outcome struct {
+	index  int
+	result *CepResult
+	err    error
+}
+
+func (c *Client) lookupError(cep string, errs []error) *LookupError {
+	e := &LookupError{Cep: cep}
+	for i, err := range errs {
+		e.Failures = append(e.Failures, &ProviderError{Provider: c.providers[i].Name(), Err: err})
+	}
+	return e
+}
+
+// Remove a pontuação do CEP, deixando os 8 dígitos
+func normalize(cep string) (string, error) {
+	digits := strings.Map(func(r rune) rune {
+		switch {
+		case r >= '0' && r <= '9':
+			return r
+		case r == '-' || r == '.' || r == ' ':
+			return -1
+		}
+		return 'x'
+	}, cep)
+	if len(digits) != 8 || strings.Contains(digits, "x") {
+		return "", ErrInvalidCep
+	}
+	return digits, nil
+}
+
+// Aceita respostas com CEP, estado e cidade; rua e bairro ficam vazios nos
+// CEPs de uma cidade inteira
+func (r *CepResult) validate(cep string) error {
+	if r == nil {
+		return ErrIncompleteResult
+	}
+	if got, err := normalize(r.Cep); err != nil || got != cep {
+		return fmt.Errorf("%w: answered for CEP %q", ErrIncompleteResult, r.Cep)
+	}
+	if r.Estado == "" || r.Cidade == "" {
+		return fmt.Errorf("%w: missing state or city", ErrIncompleteResult)
+	}
+	return nil
+}
+
+// Corpo de um GET em url. Os status em notFound viram ErrNotFound e qualquer
+// outro diferente de 200 é erro.
+func get(ctx context.Context, client *http.Client, url string, notFound ...int) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching: %w", err)
+	}
+	defer resp.Body.Close()
+	if slices.Contains(notFound, resp.StatusCode) {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New("unexpected status " + resp.Status)
+	}
+	res, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body: %w", err)
+	}
+	return res, nil
+}
@@ -0,0 +1,259 @@
+package cep
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newClient(t *testing.T, config Config) *Client {
+	t.Helper()
+	client, err := New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+// Responde body depois de delay; a URL devolvida é um formato, como as dos provedores
+func newTestAPI(t *testing.T, delay time.Duration, status int, body string) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server.URL + "/%s"
+}
+
+const (
+	viaCepBody    = `{"cep":"01153-000","logradouro":"Rua Vitorino Carmilo","bairro":"Barra Funda","localidade":"São Paulo","uf":"SP"}`
+	brasilApiBody = `{"cep":"01153000","state":"SP","city":"São Paulo","neighborhood":"Barra Funda","street":"Rua Vitorino Carmilo","service":"open-cep"}`
+)
+
+func TestLookupFirstAnswerWins(t *testing.T) {
+	tests := []struct {
+		name      string
+		viaCep    time.Duration
+		brasilApi time.Duration
+		want      CepResult
+	}{
+		{"ViaCep mais rápida", 0, 200 * time.Millisecond, CepResult{ApiUsed: "ViaCep", Cep: "01153-000", Estado: "SP", Cidade: "São Paulo", Bairro: "Barra Funda", Rua: "Rua Vitorino Carmilo"}},
+		{"BrasilApi mais rápida", 200 * time.Millisecond, 0, CepResult{ApiUsed: "BrasilApi", Cep: "01153000", Estado: "SP", Cidade: "São Paulo", Bairro: "Barra Funda", Rua: "Rua Vitorino Carmilo"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newClient(t, Config{Providers: []Provider{
+				NewViaCep(newTestAPI(t, tt.viaCep, http.StatusOK, viaCepBody), http.DefaultClient),
+				NewBrasilApi(newTestAPI(t, tt.brasilApi, http.StatusOK, brasilApiBody), http.DefaultClient),
+			}})
+			got, err := client.Lookup(context.Background(), "01153000")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("recebido %+v, esperado %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookupFailedProviderDoesNotWin(t *testing.T) {
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		url    string
+	}{
+		{"json inválido", http.StatusOK, `not json`, ""},
+		{"não encontrado", http.StatusOK, `{"erro": true}`, ""},
+		{"não encontrado como string", http.StatusOK, `{"erro": "true"}`, ""},
+		{"erro do servidor", http.StatusInternalServerError, viaCepBody, ""},
+		{"sem cidade", http.StatusOK, `{"cep":"01153-000","uf":"SP"}`, ""},
+		{"outro CEP", http.StatusOK, `{"cep":"01153-001","localidade":"São Paulo","uf":"SP"}`, ""},
+		{"erro de transporte", 0, "", closed.URL + "/%s"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := tt.url
+			if url == "" {
+				url = newTestAPI(t, 0, tt.status, tt.body)
+			}
+			client := newClient(t, Config{Providers: []Provider{
+				NewViaCep(url, http.DefaultClient),
+				NewBrasilApi(newTestAPI(t, 50*time.Millisecond, http.StatusOK, brasilApiBody), http.DefaultClient),
+			}})
+			got, err := client.Lookup(context.Background(), "01153-000")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.ApiUsed != "BrasilApi" {
+				t.Errorf("resposta da %s, esperado BrasilApi", got.ApiUsed)
+			}
+		})
+	}
+}
+
+func TestLookupNotFound(t *testing.T) {
+	client := newClient(t, Config{Providers: []Provider{
+		NewViaCep(newTestAPI(t, 0, http.StatusOK, `{"erro": true}`), http.DefaultClient),
+		NewBrasilApi(newTestAPI(t, 0, http.StatusNotFound, `{"name":"CepPromiseError","message":"Todos os serviços de CEP retornaram erro."}`), http.DefaultClient),
+	}})
+	_, err := client.Lookup(context.Background(), "99999999")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("recebido %v, esperado ErrNotFound", err)
+	}
+
+	// Não encontrado por um único provedor não é definitivo
+	partial := newClient(t, Config{Providers: []Provider{
+		NewViaCep(newTestAPI(t, 0, http.StatusOK, `{"erro": true}`), http.DefaultClient),
+		NewBrasilApi(newTestAPI(t, 0, http.StatusBadGateway, ``), http.DefaultClient),
+	}})
+	_, err = partial.Lookup(context.Background(), "99999999")
+	var lookupErr *LookupError
+	if !errors.As(err, &lookupErr) || errors.Is(err, ErrNotFound) {
+		t.Fatalf("recebido %v, esperado um LookupError que não seja ErrNotFound", err)
+	}
+	want := "CEP 99999999 lookup failed: ViaCep: CEP not found; BrasilApi: unexpected status 502 Bad Gateway"
+	if err.Error() != want {
+		t.Errorf("recebido %q, esperado %q", err.Error(), want)
+	}
+}
+
+func TestLookupInvalidCep(t *testing.T) {
+	for _, cep := range []string{"", "1234567", "011530000", "01153-00a"} {
+		if _, err := newClient(t, Config{}).Lookup(context.Background(), cep); !errors.Is(err, ErrInvalidCep) {
+			t.Errorf("%q: recebido %v, esperado ErrInvalidCep", cep, err)
+		}
+	}
+}
+
+func TestLookupErrors(t *testing.T) {
+	client := newClient(t, Config{Providers: []Provider{
+		NewViaCep(newTestAPI(t, 0, http.StatusOK, `not json`), http.DefaultClient),
+	}})
+	if _, err := client.Lookup(context.Background(), "01153000"); err == nil {
+		t.Error("esperado erro quando todos os provedores falham")
+	}
+
+	slow := newClient(t, Config{Providers: []Provider{
+		NewViaCep(newTestAPI(t, time.Second, http.StatusOK, viaCepBody), http.DefaultClient),
+	}})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := slow.Lookup(ctx, "01153000"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("recebido %v, esperado context.DeadlineExceeded", err)
+	}
+
+	if _, err := newClient(t, Config{Providers: []Provider{}}).Lookup(context.Background(), "01153000"); !errors.Is(err, ErrNoProviders) {
+		t.Errorf("recebido %v, esperado ErrNoProviders", err)
+	}
+}
+
+type instantProvider struct {
+	result CepResult
+}
+
+func (p instantProvider) Name() string {
+	return p.result.ApiUsed
+}
+
+func (p instantProvider) Fetch(ctx context.Context, cep string) (*CepResult, error) {
+	result := p.result
+	return &result, nil
+}
+
+// Bloqueia até o contexto terminar e informa o erro do contexto em done
+type blockingProvider struct {
+	done chan error
+}
+
+func (p blockingProvider) Name() string {
+	return "blocking"
+}
+
+func (p blockingProvider) Fetch(ctx context.Context, cep string) (*CepResult, error) {
+	<-ctx.Done()
+	p.done <- ctx.Err()
+	return nil, ctx.Err()
+}
+
+func TestLookupCancelsLosers(t *testing.T) {
+	loser := blockingProvider{done: make(chan error, 1)}
+	instant := instantProvider{CepResult{ApiUsed: "instant", Cep: "01153000", Estado: "SP", Cidade: "São Paulo"}}
+	client := newClient(t, Config{Timeout: time.Minute, Providers: []Provider{loser, instant}})
+	if _, err := client.Lookup(context.Background(), "01153000"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-loser.done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("perdedor parou com %v, esperado context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("o provedor perdedor continuou rodando depois da corrida vencida")
+	}
+}
+
+func TestLookupTimeout(t *testing.T) {
+	provider := blockingProvider{done: make(chan error, 1)}
+	client := newClient(t, Config{Timeout: 30 * time.Millisecond, Providers: []Provider{provider}})
+	start := time.Now()
+	if _, err := client.Lookup(context.Background(), "01153000"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("recebido %v, esperado context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("consulta levou %v com timeout de 30ms", elapsed)
+	}
+	if err := <-provider.done; !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("provedor parou com %v, esperado context.DeadlineExceeded", err)
+	}
+}
+
+func TestLookupDoesNotLeakGoroutines(t *testing.T) {
+	var cancelled atomic.Int32
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(5 * time.Second):
+			w.Write([]byte(brasilApiBody))
+		case <-r.Context().Done():
+			cancelled.Add(1)
+		}
+	}))
+	defer slow.Close()
+	fast := newTestAPI(t, 0, http.StatusOK, viaCepBody)
+	// Sem keep-alive nenhuma goroutine de conexão ociosa sobrevive às consultas
+	httpClient := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	client := newClient(t, Config{Timeout: 10 * time.Second, Providers: []Provider{
+		NewViaCep(fast, httpClient),
+		NewBrasilApi(slow.URL+"/%s", httpClient),
+	}})
+
+	before := runtime.NumGoroutine()
+	const lookups = 20
+	for i := 0; i < lookups; i++ {
+		if _, err := client.Lookup(context.Background(), "01153000"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for cancelled.Load() < lookups || runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d de %d requisições lentas canceladas, %d goroutines restantes", cancelled.Load(), lookups, runtime.NumGoroutine()-before)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,73 @@
+package cep
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrNoProviders = errors.New("no CEP providers configured")
+	// Devolvido antes de chamar qualquer provedor
+	ErrInvalidCep = errors.New("invalid CEP: must have 8 digits")
+	// Devolvido pelo provedor que não conhece o CEP, e pelo Lookup só quando
+	// todos os provedores disseram isso
+	ErrNotFound = errors.New("CEP not found")
+	// Resposta sem CEP, estado ou cidade, ou de outro CEP
+	ErrIncompleteResult = errors.New("incomplete result")
+)
+
+// Falha de um único provedor
+type ProviderError struct {
+	Provider string
+	Err      error
+}
+
+func (e *ProviderError) Error() string {
+	return e.Provider + ": " + e.Err.Error()
+}
+
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
+
+// Falha de cada provedor quando nenhum respondeu com sucesso.
+//
+// errors.Is(err, ErrNotFound) só é verdadeiro quando todos os provedores
+// responderam que o CEP não existe; qualquer outra falha (ex:
+// context.DeadlineExceeded) corresponde se aconteceu em ao menos um.
+type LookupError struct {
+	Cep      string
+	Failures []*ProviderError
+}
+
+func (e *LookupError) Error() string {
+	failures := make([]string, 0, len(e.Failures))
+	for _, f := range e.Failures {
+		failures = append(failures, f.Error())
+	}
+	return "CEP " + e.Cep + " lookup failed: " + strings.Join(failures, "; ")
+}
+
+func (e *LookupError) Is(target error) bool {
+	if target != ErrNotFound {
+		return false
+	}
+	for _, f := range e.Failures {
+		if !errors.Is(f.Err, ErrNotFound) {
+			return false
+		}
+	}
+	return len(e.Failures) > 0
+}
+
+// Deixa de fora as respostas de não encontrado, para que um não encontrado
+// parcial não seja confundido com um definitivo
+func (e *LookupError) Unwrap() []error {
+	errs := []error{}
+	for _, f := range e.Failures {
+		if !errors.Is(f.Err, ErrNotFound) {
+			errs = append(errs, f)
+		}
+	}
+	return errs
+}
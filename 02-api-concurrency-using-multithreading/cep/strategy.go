@@ -0,0 +1,158 @@
+package cep
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Define quando cada provedor é chamado durante uma consulta
+type Strategy string
+
+const (
+	// Chama todos os provedores ao mesmo tempo; vence a primeira resposta válida
+	StrategyRace Strategy = "race"
+	// Chama os provedores em ordem, começando o próximo quando o anterior
+	// falha ou demora mais que o hedge delay
+	StrategyHedged Strategy = "hedged"
+	// Chama o próximo provedor só quando o anterior falha
+	StrategySequential Strategy = "sequential-fallback"
+	// Sorteia a ordem pela taxa de sucesso e pela latência observadas de
+	// cada provedor, com fallback sequencial
+	StrategyWeighted Strategy = "weighted"
+)
+
+// Usado pela StrategyHedged quando Config.HedgeDelay não é informado e ainda
+// não há latências suficientes para o Config.HedgePercentile
+const DefaultHedgeDelay = 250 * time.Millisecond
+
+var (
+	Strategies         = []Strategy{StrategyRace, StrategyHedged, StrategySequential, StrategyWeighted}
+	ErrUnknownStrategy = errors.New("unknown strategy")
+	// HedgePercentile precisa estar em (0, 1]; zero o desliga
+	ErrInvalidHedgePercentile = errors.New("invalid hedge percentile")
+)
+
+func ParseStrategy(s string) (Strategy, error) {
+	if !slices.Contains(Strategies, Strategy(s)) {
+		return "", fmt.Errorf("%w: %s", ErrUnknownStrategy, s)
+	}
+	return Strategy(s), nil
+}
+
+const (
+	// Latências de sucesso guardadas por provedor para o percentil do hedge
+	maxLatencySamples = 100
+	// Amostras necessárias para o percentil substituir o hedge delay fixo
+	minLatencySamples = 10
+	// Peso da última chamada nas médias móveis da StrategyWeighted
+	statsAlpha = 0.2
+	// Latência assumida para provedores ainda não observados
+	initialLatency = 100 * time.Millisecond
+	// Menor taxa de sucesso considerada, para que um provedor com falhas
+	// ainda seja chamado de vez em quando e possa recuperar o peso
+	minSuccessRate = 0.05
+)
+
+// Chamadas observadas de um provedor. As abortadas porque outro provedor
+// venceu não contam.
+type providerStats struct {
+	mu        sync.Mutex
+	latencies []time.Duration
+	next      int
+	success   float64
+	latency   float64
+}
+
+func newProviderStats() *providerStats {
+	return &providerStats{success: 1, latency: float64(initialLatency)}
+}
+
+func (s *providerStats) observe(latency time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Não encontrado é uma resposta válida de um provedor saudável
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		s.success += statsAlpha * (0 - s.success)
+		return
+	}
+	s.success += statsAlpha * (1 - s.success)
+	s.latency += statsAlpha * (float64(latency) - s.latency)
+	if len(s.latencies) < maxLatencySamples {
+		s.latencies = append(s.latencies, latency)
+	} else {
+		s.latencies[s.next] = latency
+		s.next = (s.next + 1) % maxLatencySamples
+	}
+}
+
+// Percentil das latências de sucesso recentes, se houver amostras suficientes
+func (s *providerStats) percentile(p float64) (time.Duration, bool) {
+	s.mu.Lock()
+	if len(s.latencies) < minLatencySamples {
+		s.mu.Unlock()
+		return 0, false
+	}
+	sorted := slices.Clone(s.latencies)
+	s.mu.Unlock()
+	slices.Sort(sorted)
+	return sorted[int(p*float64(len(sorted)-1))], true
+}
+
+// O peso cresce com a taxa de sucesso e diminui com a latência
+func (s *providerStats) weight() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return max(s.success, minSuccessRate) / max(s.latency, 1)
+}
+
+// Ordem das chamadas aos provedores; no weighted cada posição é sorteada na
+// proporção dos pesos, então o tráfego segue o melhor provedor sem deixar os
+// outros sem observações
+func (c *Client) order() []int {
+	order := make([]int, len(c.providers))
+	for i := range order {
+		order[i] = i
+	}
+	if c.strategy != StrategyWeighted {
+		return order
+	}
+	weights := make([]float64, len(order))
+	for i := range order {
+		weights[i] = c.stats[i].weight()
+	}
+	for pos := range order {
+		total := 0.0
+		for _, i := range order[pos:] {
+			total += weights[i]
+		}
+		pick := rand.Float64() * total
+		for j := pos; j < len(order); j++ {
+			pick -= weights[order[j]]
+			if pick <= 0 || j == len(order)-1 {
+				order[pos], order[j] = order[j], order[pos]
+				break
+			}
+		}
+	}
+	return order
+}
+
+// Quanto esperar pelo provedor i antes de chamar o próximo
+func (c *Client) hedgeDelay(i int) time.Duration {
+	if c.hedgePercentile > 0 {
+		if d, ok := c.stats[i].percentile(c.hedgePercentile); ok {
+			return d
+		}
+	}
+	return c.hedgeAfter
+}
+
+// Chamada abortada porque a consulta já retornou
+func lostRace(ctx context.Context, err error) bool {
+	return errors.Is(err, context.Canceled) && errors.Is(ctx.Err(), context.Canceled)
+}
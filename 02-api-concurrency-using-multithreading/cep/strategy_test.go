@@ -0,0 +1,164 @@
+package cep
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Responde depois de delay, com err ou com um resultado completo, contando as chamadas
+type stubProvider struct {
+	name  string
+	delay time.Duration
+	err   error
+	calls atomic.Int32
+	// Momento da primeira chamada, a partir do início do teste
+	startedAt atomic.Int64
+	since     time.Time
+}
+
+func newStubProvider(name string, delay time.Duration, err error) *stubProvider {
+	return &stubProvider{name: name, delay: delay, err: err, since: time.Now()}
+}
+
+func (p *stubProvider) Name() string {
+	return p.name
+}
+
+func (p *stubProvider) Fetch(ctx context.Context, cep string) (*CepResult, error) {
+	if p.calls.Add(1) == 1 {
+		p.startedAt.Store(int64(time.Since(p.since)))
+	}
+	select {
+	case <-time.After(p.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &CepResult{ApiUsed: p.name, Cep: cep, Estado: "SP", Cidade: "São Paulo"}, nil
+}
+
+func TestStrategies(t *testing.T) {
+	failure := errors.New("unavailable")
+	tests := []struct {
+		name      string
+		strategy  Strategy
+		primary   *stubProvider
+		secondary *stubProvider
+		winner    string
+		// Se o provedor secundário precisa ser chamado
+		secondaryCalled bool
+	}{
+		{"race chama os dois", StrategyRace, newStubProvider("primary", 50*time.Millisecond, nil), newStubProvider("secondary", time.Second, nil), "primary", true},
+		{"sequential para no primeiro sucesso", StrategySequential, newStubProvider("primary", 50*time.Millisecond, nil), newStubProvider("secondary", 0, nil), "primary", false},
+		{"sequential passa para o próximo na falha", StrategySequential, newStubProvider("primary", 0, failure), newStubProvider("secondary", 0, nil), "secondary", true},
+		{"hedged não chama o segundo provedor quando o primeiro é rápido", StrategyHedged, newStubProvider("primary", 0, nil), newStubProvider("secondary", 0, nil), "primary", false},
+		{"hedged chama o segundo provedor depois do atraso", StrategyHedged, newStubProvider("primary", time.Second, nil), newStubProvider("secondary", 0, nil), "secondary", true},
+		{"hedged passa para o próximo na falha", StrategyHedged, newStubProvider("primary", 0, failure), newStubProvider("secondary", 0, nil), "secondary", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newClient(t, Config{
+				Providers:  []Provider{tt.primary, tt.secondary},
+				Strategy:   tt.strategy,
+				HedgeDelay: 100 * time.Millisecond,
+				Timeout:    2 * time.Second,
+			})
+			got, err := client.Lookup(context.Background(), "01153000")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.ApiUsed != tt.winner {
+				t.Errorf("resposta de %s, esperado %s", got.ApiUsed, tt.winner)
+			}
+			if called := tt.secondary.calls.Load() > 0; called != tt.secondaryCalled {
+				t.Errorf("secundário chamado = %v, esperado %v", called, tt.secondaryCalled)
+			}
+		})
+	}
+}
+
+func TestHedgedStartsAfterDelay(t *testing.T) {
+	primary := newStubProvider("primary", time.Second, nil)
+	secondary := newStubProvider("secondary", 0, nil)
+	client := newClient(t, Config{Providers: []Provider{primary, secondary}, Strategy: StrategyHedged, HedgeDelay: 80 * time.Millisecond})
+	if _, err := client.Lookup(context.Background(), "01153000"); err != nil {
+		t.Fatal(err)
+	}
+	if started := time.Duration(secondary.startedAt.Load()); started < 80*time.Millisecond || started > 500*time.Millisecond {
+		t.Errorf("secundário começou depois de %v, esperado cerca de 80ms", started)
+	}
+}
+
+func TestHedgedUsesLatencyPercentile(t *testing.T) {
+	primary := newStubProvider("primary", time.Second, nil)
+	secondary := newStubProvider("secondary", 0, nil)
+	client := newClient(t, Config{
+		Providers:       []Provider{primary, secondary},
+		Strategy:        StrategyHedged,
+		HedgeDelay:      time.Minute,
+		HedgePercentile: 0.9,
+		Timeout:         2 * time.Second,
+	})
+	for i := 0; i < minLatencySamples; i++ {
+		client.stats[0].observe(20*time.Millisecond, nil)
+	}
+	start := time.Now()
+	if _, err := client.Lookup(context.Background(), "01153000"); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("consulta levou %v, o percentil 90 da latência do primário é 20ms", elapsed)
+	}
+}
+
+func TestWeightedPrefersHealthyProvider(t *testing.T) {
+	failing := newStubProvider("failing", 0, errors.New("unavailable"))
+	healthy := newStubProvider("healthy", time.Millisecond, nil)
+	client := newClient(t, Config{Providers: []Provider{failing, healthy}, Strategy: StrategyWeighted})
+	const lookups = 50
+	for i := 0; i < lookups; i++ {
+		got, err := client.Lookup(context.Background(), "01153000")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.ApiUsed != "healthy" {
+			t.Fatalf("resposta de %s", got.ApiUsed)
+		}
+	}
+	if calls := failing.calls.Load(); calls > 10 {
+		t.Errorf("provedor com falha chamado %d vezes em %d consultas", calls, lookups)
+	}
+}
+
+func TestParseStrategy(t *testing.T) {
+	for _, s := range Strategies {
+		if got, err := ParseStrategy(string(s)); err != nil || got != s {
+			t.Errorf("ParseStrategy(%q) = %q, %v", s, got, err)
+		}
+	}
+	if _, err := ParseStrategy("fastest"); !errors.Is(err, ErrUnknownStrategy) {
+		t.Errorf("recebido %v, esperado ErrUnknownStrategy", err)
+	}
+	if _, err := New(Config{Strategy: "fastest"}); !errors.Is(err, ErrUnknownStrategy) {
+		t.Errorf("recebido %v, esperado ErrUnknownStrategy", err)
+	}
+}
+
+func TestNewRejectsHedgePercentile(t *testing.T) {
+	for _, p := range []float64{-0.5, 1.5, 90, math.NaN()} {
+		if _, err := New(Config{Strategy: StrategyHedged, HedgePercentile: p}); !errors.Is(err, ErrInvalidHedgePercentile) {
+			t.Errorf("HedgePercentile %v: recebido %v, esperado ErrInvalidHedgePercentile", p, err)
+		}
+	}
+	for _, p := range []float64{0, 0.5, 1} {
+		if _, err := New(Config{Strategy: StrategyHedged, HedgePercentile: p}); err != nil {
+			t.Errorf("HedgePercentile %v: %v", p, err)
+		}
+	}
+}
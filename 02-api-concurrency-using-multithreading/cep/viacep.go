@@ -0,0 +1,62 @@
+package cep
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Formato da URL, que recebe o CEP
+const ViaCepURL = "https://viacep.com.br/ws/%s/json"
+
+type viaCepResponse struct {
+	Cep         string `json:"cep"`
+	Logradouro  string `json:"logradouro"`
+	Complemento string `json:"complemento"`
+	Unidade     string `json:"unidade"`
+	Bairro      string `json:"bairro"`
+	Localidade  string `json:"localidade"`
+	Uf          string `json:"uf"`
+	Ibge        string `json:"ibge"`
+	Gia         string `json:"gia"`
+	Ddd         string `json:"ddd"`
+	Siafi       string `json:"siafi"`
+	// true (ou "true") para CEPs desconhecidos, respondidos com status 200
+	Erro any `json:"erro"`
+}
+
+type ViaCep struct {
+	url    string
+	client *http.Client
+}
+
+func NewViaCep(url string, client *http.Client) *ViaCep {
+	return &ViaCep{url: url, client: client}
+}
+
+func (v *ViaCep) Name() string {
+	return "ViaCep"
+}
+
+func (v *ViaCep) Fetch(ctx context.Context, cep string) (*CepResult, error) {
+	res, err := get(ctx, v.client, fmt.Sprintf(v.url, cep), http.StatusNotFound)
+	if err != nil {
+		return nil, err
+	}
+	var viaCep viaCepResponse
+	if err := json.Unmarshal(res, &viaCep); err != nil {
+		return nil, fmt.Errorf("error unmarshaling response: %w", err)
+	}
+	if viaCep.Erro == true || viaCep.Erro == "true" {
+		return nil, ErrNotFound
+	}
+	return &CepResult{
+		ApiUsed: v.Name(),
+		Cep:     viaCep.Cep,
+		Estado:  viaCep.Uf,
+		Cidade:  viaCep.Localidade,
+		Bairro:  viaCep.Bairro,
+		Rua:     viaCep.Logradouro,
+	}, nil
+}
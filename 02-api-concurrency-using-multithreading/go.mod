@@ -0,0 +1,3 @@
+module github.com/isaacmirandacampos/go-expert/02-api-concurrency-using-multithreading
+
+go 1.22.5
@@ -2,121 +2,69 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
-	"time"
-)
+	"os/signal"
 
-var (
-	viaCepURL    = "https://viacep.com.br/ws/%s/json"
-	brasilApiURL = "https://brasilapi.com.br/api/cep/v1/%s"
+	"github.com/isaacmirandacampos/go-expert/02-api-concurrency-using-multithreading/cep"
 )
 
-type BrasilApi struct {
-	Cep          string `json:"cep"`
-	State        string `json:"state"`
-	City         string `json:"city"`
-	Neighborhood string `json:"neighborhood"`
-	Street       string `json:"street"`
-	Service      string `json:"service"`
-}
-
-type ViaCep struct {
-	Cep         string `json:"cep"`
-	Logradouro  string `json:"logradouro"`
-	Complemento string `json:"complemento"`
-	Unidade     string `json:"unidade"`
-	Bairro      string `json:"bairro"`
-	Localidade  string `json:"localidade"`
-	Uf          string `json:"uf"`
-	Ibge        string `json:"ibge"`
-	Gia         string `json:"gia"`
-	Ddd         string `json:"ddd"`
-	Siafi       string `json:"siafi"`
-}
-
-type CepResult struct {
-	ApiUsed string `json:"api_used"`
-	Cep     string `json:"cep"`
-	Estado  string `json:"estado"`
-	Cidade  string `json:"cidade"`
-	Bairro  string `json:"bairro"`
-	Rua     string `json:"rua"`
-}
-
 func main() {
-	if len(os.Args) < 2 {
-		panic("Please provide a CEP to fetch")
+	timeout := flag.Duration("timeout", cep.DefaultTimeout, "maximum time to wait for an answer")
+	strategyName := flag.String("strategy", string(cep.StrategyRace), "race, hedged, sequential-fallback or weighted")
+	hedgeDelay := flag.Duration("hedge-delay", cep.DefaultHedgeDelay, "hedged: time to wait for a provider before calling the next")
+	hedgePercentile := flag.Float64("hedge-percentile", 0, "hedged: wait for this percentile (0-1) of the provider's recent latencies instead of -hedge-delay")
+	var batch batchConfig
+	flag.StringVar(&batch.input, "batch", "", `look up every CEP of this file ("-" for stdin), one per line`)
+	flag.StringVar(&batch.column, "column", "", "batch: read the CEPs from this column of a CSV file with a header")
+	flag.StringVar(&batch.format, "format", "csv", "batch: output format, csv or jsonl")
+	flag.StringVar(&batch.output, "output", "-", `batch: output file, "-" for stdout`)
+	flag.IntVar(&batch.workers, "workers", cep.DefaultWorkers, "batch: concurrent lookups")
+	providerLimit := flag.Int("provider-limit", 4, "batch: maximum concurrent requests to each API, 0 for no limit")
+	flag.Parse()
+	strategy, err := cep.ParseStrategy(*strategyName)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+	config := cep.Config{Timeout: *timeout, Strategy: strategy, HedgeDelay: *hedgeDelay, HedgePercentile: *hedgePercentile}
+	if batch.input != "" {
+		config.ProviderConcurrency = *providerLimit
+	}
+	client, err := cep.New(config)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
 	}
 
-	cep := os.Args[1]
-	fetchCEPConcurrentlyWithChannel(cep)
-}
-
-func fetchCEPConcurrentlyWithChannel(cep string) {
-	cepResult := make(chan *CepResult, 2) // Using 2 since we're fetching from two APIs
-	go fetch(cep, viaCepURL, "ViaCep", cepResult)
-	go fetch(cep, brasilApiURL, "BrasilApi", cepResult)
-	select {
-	case res := <-cepResult:
-		fmt.Printf("Fetched from %s: %+v\n", res.ApiUsed, res)
-	case <-time.After(1 * time.Second):
-		fmt.Println("Exceeded time limit")
+	if batch.input != "" {
+		// Ctrl-C interrompe as consultas mas mantém os resultados já escritos
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		if err := runBatch(ctx, client, batch); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
 		return
 	}
-}
 
-func fetch(cep string, url string, apiName string, cepResult chan<- *CepResult) {
-	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
-	defer cancel()
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(url, cep), nil)
-	if err != nil {
-		fmt.Printf("Error creating request to %s: %v\n", apiName, err)
-		return
+	if flag.NArg() < 1 {
+		panic("Please provide a CEP to fetch")
 	}
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		fmt.Printf("Error fetching from %s: %v\n", apiName, err)
+	res, err := client.Lookup(context.Background(), flag.Arg(0))
+	if errors.Is(err, context.DeadlineExceeded) {
+		fmt.Println("Exceeded time limit")
+		os.Exit(1)
 	}
-	defer resp.Body.Close()
-	res, err := io.ReadAll(resp.Body)
-	if err != nil {
-		fmt.Printf("Error reading response body: %v\n", err)
-		return
+	if errors.Is(err, cep.ErrNotFound) {
+		fmt.Println("CEP not found")
+		os.Exit(1)
 	}
-	var result CepResult
-	switch apiName {
-	case "ViaCep":
-		var viaCep ViaCep
-		if err := json.Unmarshal(res, &viaCep); err != nil {
-			fmt.Printf("Error unmarshaling ViaCep response: %v\n", err)
-			return
-		}
-		result = CepResult{
-			ApiUsed: apiName,
-			Cep:     viaCep.Cep,
-			Estado:  viaCep.Uf,
-			Cidade:  viaCep.Localidade,
-			Bairro:  viaCep.Bairro,
-			Rua:     viaCep.Logradouro,
-		}
-	case "BrasilApi":
-		var brasilApi BrasilApi
-		if err := json.Unmarshal(res, &brasilApi); err != nil {
-			fmt.Printf("Error unmarshaling BasilApi response: %v\n", err)
-			return
-		}
-		result = CepResult{
-			ApiUsed: apiName,
-			Cep:     brasilApi.Cep,
-			Estado:  brasilApi.State,
-			Cidade:  brasilApi.City,
-			Bairro:  brasilApi.Neighborhood,
-			Rua:     brasilApi.Street,
-		}
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
 	}
-	cepResult <- &result
+	fmt.Printf("Fetched from %s: %+v\n", res.ApiUsed, res)
 }